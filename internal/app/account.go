@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	organizationstypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/smithy-go"
+
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+const (
+	_organizationAccounts = "organization"
+	_accountRole          = "OrganizationAccountAccessRole"
+	_sessionName          = "msk-secret-binder"
+	_operatorTagKey       = "operator"
+)
+
+type OrganizationsClientAPI interface {
+	ListAccounts(context.Context, *organizations.ListAccountsInput, ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error)
+}
+
+// newFleet builds a service for every account and region combination. Without
+// accounts the default credentials are used for a single unnamed account. Write
+// roles are assumed from the base credentials so they need not trust the read
+// role.
+func newFleet(base, cfg aws.Config, opts *Options, caller string) (*Fleet, error) {
+	accounts, err := resolveAccounts(cfg, opts.accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	fleet := &Fleet{}
+	for _, account := range accounts {
+		accountCfg := cfg
+		if account != "" {
+			accountCfg = assumeAccountRole(cfg, opts, account)
+		}
+
+		regions, err := resolveRegions(accountCfg, opts.regions)
+		if err != nil {
+			if account != "" {
+				return nil, fmt.Errorf("%v: %w", account, err)
+			}
+			return nil, err
+		}
+
+		writeCfg, writer := writeConfig(base, opts, account, caller)
+
+		for _, region := range regions {
+			regionCfg := accountCfg.Copy()
+			regionCfg.Region = region
+			svc := newService(regionCfg, opts.aws.endpoints)
+			svc.account = account
+			svc.pageSize = opts.config.PageSize
+
+			if writer {
+				regionCfg = writeCfg.Copy()
+				regionCfg.Region = region
+				w := newService(regionCfg, opts.aws.endpoints)
+				svc.kafkaWriter = w.kafka
+				svc.secretsmanagerWriter = w.secretsmanager
+			}
+
+			fleet.services = append(fleet.services, svc)
+		}
+	}
+
+	return fleet, nil
+}
+
+func resolveAccounts(cfg aws.Config, accounts string) ([]string, error) {
+	switch accounts {
+	case "":
+		return []string{""}, nil
+	case _organizationAccounts:
+		return listAccounts(organizations.NewFromConfig(cfg))
+	}
+
+	return parseList(accounts), nil
+}
+
+func assumeAccountRole(cfg aws.Config, opts *Options, account string) aws.Config {
+	return assumeRole(cfg, opts.aws, accountRoleArn(account, opts.accountRole))
+}
+
+func accountRoleArn(account, role string) string {
+	return fmt.Sprintf("arn:aws:iam::%v:role/%v", account, role)
+}
+
+// writeConfig returns the config for the clients that change associations when
+// separate write credentials are configured. The session is tagged with the
+// operator so changes can be attributed in CloudTrail.
+func writeConfig(cfg aws.Config, opts *Options, account, caller string) (aws.Config, bool) {
+	var roleArn string
+	switch {
+	case account != "" && opts.writeAccountRole != "":
+		roleArn = accountRoleArn(account, opts.writeAccountRole)
+	case account == "" && opts.aws.writeRoleArn != "":
+		roleArn = opts.aws.writeRoleArn
+	default:
+		return cfg, false
+	}
+
+	tags := []ststypes.Tag{
+		{Key: aws.String(_operatorTagKey), Value: aws.String(caller)},
+	}
+
+	return assumeRole(cfg, opts.aws, roleArn, tags...), true
+}
+
+// listAccounts returns the active accounts in the organization. It must be
+// called with credentials from the management or a delegated administrator
+// account.
+func listAccounts(cl OrganizationsClientAPI) ([]string, error) {
+	accounts := []string{}
+
+	paginator := organizations.NewListAccountsPaginator(cl, &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) {
+				return nil, fmt.Errorf("unable to list accounts: %v", apiErr.ErrorMessage())
+			}
+			return nil, fmt.Errorf("unable to list accounts: %w", err)
+		}
+
+		for _, account := range output.Accounts {
+			if account.Status != organizationstypes.AccountStatusActive {
+				continue
+			}
+			accounts = append(accounts, aws.ToString(account.Id))
+		}
+	}
+	sort.Strings(accounts)
+
+	return accounts, nil
+}
@@ -0,0 +1,302 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	organizationstypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockOrganizationsClientAPI struct {
+	pages [][]organizationstypes.Account
+	err   error
+}
+
+func (m mockOrganizationsClientAPI) ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	page := 0
+	if params.NextToken != nil {
+		page, _ = strconv.Atoi(aws.ToString(params.NextToken))
+	}
+
+	output := &organizations.ListAccountsOutput{Accounts: m.pages[page]}
+	if page+1 < len(m.pages) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+
+	return output, nil
+}
+
+func TestListAccounts(t *testing.T) {
+	account := func(id string, status organizationstypes.AccountStatus) organizationstypes.Account {
+		return organizationstypes.Account{Id: aws.String(id), Status: status}
+	}
+
+	tests := []struct {
+		name    string
+		give    mockOrganizationsClientAPI
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "single_page",
+			give: mockOrganizationsClientAPI{pages: [][]organizationstypes.Account{
+				{account("222222222222", organizationstypes.AccountStatusActive), account("111111111111", organizationstypes.AccountStatusActive)},
+			}},
+			want: []string{"111111111111", "222222222222"},
+		}, {
+			name: "multiple_pages",
+			give: mockOrganizationsClientAPI{pages: [][]organizationstypes.Account{
+				{account("111111111111", organizationstypes.AccountStatusActive)},
+				{account("222222222222", organizationstypes.AccountStatusActive)},
+			}},
+			want: []string{"111111111111", "222222222222"},
+		}, {
+			name: "suspended",
+			give: mockOrganizationsClientAPI{pages: [][]organizationstypes.Account{
+				{account("111111111111", organizationstypes.AccountStatusActive), account("222222222222", organizationstypes.AccountStatusSuspended)},
+			}},
+			want: []string{"111111111111"},
+		}, {
+			name:    "error",
+			give:    mockOrganizationsClientAPI{err: errors.New("denied")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listAccounts(tt.give)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestNewFleetAccounts(t *testing.T) {
+	opts := &Options{
+		accounts:    "111111111111,222222222222",
+		accountRole: "binder",
+		regions:     "eu-west-1,us-east-1",
+		config:      defaultConfig(),
+	}
+
+	fleet, err := newFleet(aws.Config{Region: "us-east-1"}, aws.Config{Region: "us-east-1"}, opts, "operator")
+	assert.Nil(t, err)
+	assert.True(t, fleet.multiAccount())
+
+	got := []string{}
+	for _, svc := range fleet.services {
+		got = append(got, svc.location())
+	}
+	td.Cmp(t, got, []string{
+		"111111111111/eu-west-1",
+		"111111111111/us-east-1",
+		"222222222222/eu-west-1",
+		"222222222222/us-east-1",
+	})
+}
+
+func TestWriteConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		giveOpts    *Options
+		giveAccount string
+		want        bool
+	}{
+		{
+			name:     "none",
+			giveOpts: &Options{},
+		}, {
+			name:     "write_role_arn",
+			giveOpts: &Options{aws: AWSOptions{writeRoleArn: "arn:aws:iam::111111111111:role/writer"}},
+			want:     true,
+		}, {
+			name:        "write_account_role",
+			giveOpts:    &Options{writeAccountRole: "writer"},
+			giveAccount: "111111111111",
+			want:        true,
+		}, {
+			name:        "account_without_write_account_role",
+			giveOpts:    &Options{aws: AWSOptions{writeRoleArn: "arn:aws:iam::111111111111:role/writer"}},
+			giveAccount: "111111111111",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.giveOpts.accounts = tt.giveAccount
+			tt.giveOpts.config = defaultConfig()
+
+			fleet, err := newFleet(aws.Config{Region: "us-east-1"}, aws.Config{Region: "us-east-1"}, tt.giveOpts, "operator")
+			assert.Nil(t, err)
+
+			svc := fleet.services[0]
+			assert.Equal(t, tt.want, svc.kafkaWriter != nil)
+			assert.Equal(t, tt.want, svc.secretsmanagerWriter != nil)
+			assert.NotNil(t, svc.writeKafka())
+			assert.NotNil(t, svc.writeSecretsManager())
+		})
+	}
+}
+
+func TestWriteSessionCaller(t *testing.T) {
+	type assumeRole struct {
+		accessKey string
+		roleArn   string
+		tags      map[string]string
+	}
+
+	var mu sync.Mutex
+	assumed := []assumeRole{}
+
+	stsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// The access key is the first part of the signing credential scope.
+		auth := r.Header.Get("Authorization")
+		accessKey := strings.SplitN(strings.SplitN(auth, "Credential=", 2)[1], "/", 2)[0]
+
+		w.Header().Set("Content-Type", "text/xml")
+		switch r.PostForm.Get("Action") {
+		case "GetCallerIdentity":
+			arn := "arn:aws:sts::111111111111:assumed-role/unexpected/" + accessKey
+			if accessKey == "AKIDBASE" {
+				arn = "arn:aws:iam::111111111111:user/alice"
+			}
+			fmt.Fprintf(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult><Arn>%v</Arn></GetCallerIdentityResult>
+</GetCallerIdentityResponse>`, arn)
+		case "AssumeRole":
+			tags := map[string]string{}
+			for i := 1; r.PostForm.Get(fmt.Sprintf("Tags.member.%v.Key", i)) != ""; i++ {
+				tags[r.PostForm.Get(fmt.Sprintf("Tags.member.%v.Key", i))] = r.PostForm.Get(fmt.Sprintf("Tags.member.%v.Value", i))
+			}
+			roleArn := r.PostForm.Get("RoleArn")
+			mu.Lock()
+			assumed = append(assumed, assumeRole{accessKey: accessKey, roleArn: roleArn, tags: tags})
+			mu.Unlock()
+			fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>AKID%v</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token</SessionToken>
+      <Expiration>2030-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`, roleArn[strings.LastIndex(roleArn, "/")+1:])
+		default:
+			http.Error(w, "unexpected action", http.StatusBadRequest)
+		}
+	}))
+	defer stsServer.Close()
+
+	kafkaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"clusterInfoList":[]}`)
+	}))
+	defer kafkaServer.Close()
+
+	path := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(path, []byte(""), 0o600))
+	t.Setenv("AWS_CONFIG_FILE", path)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDBASE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	opts := &Options{
+		aws: AWSOptions{
+			region:       "us-east-1",
+			roleArn:      "arn:aws:iam::111111111111:role/reader",
+			writeRoleArn: "arn:aws:iam::111111111111:role/writer",
+			sessionName:  _sessionName,
+			endpoints:    Endpoints{sts: stsServer.URL, kafka: kafkaServer.URL},
+		},
+		config: defaultConfig(),
+	}
+
+	base, caller, err := loadCallerConfig(opts.aws)
+	if !assert.Nil(t, err) {
+		return
+	}
+	td.Cmp(t, caller, "arn:aws:iam::111111111111:user/alice")
+
+	fleet, err := newFleet(base, readConfig(base, opts.aws), opts, caller)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	svc := fleet.services[0]
+	_, err = listClusters(svc.kafka, 100)
+	assert.Nil(t, err)
+	_, err = listClusters(svc.writeKafka(), 100)
+	assert.Nil(t, err)
+
+	td.Cmp(t, assumed, []assumeRole{
+		{accessKey: "AKIDBASE", roleArn: "arn:aws:iam::111111111111:role/reader", tags: map[string]string{}},
+		{
+			accessKey: "AKIDBASE",
+			roleArn:   "arn:aws:iam::111111111111:role/writer",
+			tags:      map[string]string{"operator": "arn:aws:iam::111111111111:user/alice"},
+		},
+	})
+}
+
+func TestClusterLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		give *Service
+		want string
+	}{
+		{
+			name: "no_service",
+			want: "example1",
+		}, {
+			name: "region",
+			give: &Service{region: "ap-southeast-2"},
+			want: "example1",
+		}, {
+			name: "account",
+			give: &Service{account: "111111111111", region: "ap-southeast-2"},
+			want: "example1 (111111111111/ap-southeast-2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &Cluster{
+				clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("example1")},
+				service:     tt.give,
+			}
+			td.Cmp(t, clusterLabel(cluster), tt.want)
+		})
+	}
+}
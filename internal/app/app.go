@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -20,29 +21,119 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-func Run() error {
-	svc, err := newService()
+const (
+	_clusterTagKey = "Cluster"
+)
+
+func Run(args []string) error {
+	command := "apply"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "apply":
+		return runApply(args)
+	case "history":
+		return runHistory(args)
+	case "rollback":
+		return runRollback(args)
+	case "force-unlock":
+		return runForceUnlock(args)
+	case "config":
+		return runConfig(args)
+	}
+
+	return fmt.Errorf("unknown command: %v", command)
+}
+
+func runApply(args []string) error {
+	opts, err := parseOptions("apply", args)
 	if err != nil {
-		return fmt.Errorf("unable to create new service: %w", err)
+		return err
 	}
 
-	spin, err := spinner.NewSpinner()
+	if !opts.resume {
+		if err := checkNoCheckpoint(opts.checkpoint); err != nil {
+			return err
+		}
+	}
+
+	fleet, spin, err := prepareRun(opts)
 	if err != nil {
-		return fmt.Errorf("unable to create spinner: %w", err)
+		return err
+	}
+
+	if opts.resume {
+		return resumeApply(fleet, opts, spin)
 	}
 
 	fmt.Println("Bind secrets to AWS MSK clusters.")
 	fmt.Println()
 
+	if err := retrieveData(fleet, opts, spin, nil); err != nil {
+		return err
+	}
+
+	return confirmAndApply(fleet, opts, spin, planClusters)
+}
+
+func prepareRun(opts *Options) (*Fleet, *yacspin.Spinner, error) {
+	base, caller, err := loadCallerConfig(opts.aws)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := readConfig(base, opts.aws)
+
+	fleet, err := newFleet(base, cfg, opts, caller)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fleet.locker, err = newLocker(opts.lock, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create lock: %w", err)
+	}
+
+	spin, err := spinner.NewSpinner(opts.config.Spinner.Frequency, opts.config.Spinner.CharSet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create spinner: %w", err)
+	}
+
+	fleet.journal, err = newJournal(opts.journal, caller)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create journal: %w", err)
+	}
+
+	return fleet, spin, nil
+}
+
+func retrieveData(fleet *Fleet, opts *Options, spin *yacspin.Spinner, keep func(*Cluster) bool) error {
 	spin.Start()
 	spin.Message("list kafka clusters and secretsmanager secrets")
-	if err := listClustersSecrets(svc); err != nil {
+
+	g := new(errgroup.Group)
+	for _, svc := range fleet.services {
+		svc := svc
+		g.Go(func() error {
+			if err := listClustersSecrets(svc, opts); err != nil {
+				return fmt.Errorf("%v: %w", svc.location(), err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
 		spin.StopFail()
 		return err
 	}
 
+	for _, svc := range fleet.services {
+		filterService(svc, &opts.filters, keep)
+	}
+
 	spin.Message("list scram secrets")
-	if err := listScramSecretsByCluster(svc, spin); err != nil {
+	if err := listScramSecretsByCluster(fleet.clusters(), spin); err != nil {
 		spin.StopFail()
 		return err
 	}
@@ -51,45 +142,168 @@ func Run() error {
 	spin.Stop()
 	fmt.Println()
 
-	for _, cluster := range svc.clusters {
-		mapSecretsToClusters(cluster, svc.secrets)
-		reconcileClusterSecrets(cluster)
+	return nil
+}
+
+func confirmAndApply(fleet *Fleet, opts *Options, spin *yacspin.Spinner, plan planFunc) error {
+	if err := planFleet(fleet, opts, plan, time.Now()); err != nil {
+		return fmt.Errorf("unable to plan changes: %w", err)
 	}
 
-	printOverview(svc.clusters)
-	printChangeSet(svc.clusters)
+	var waves []*Wave
+	var lock *LockInfo
+	for {
+		printPlan(fleet, opts)
 
-	fmt.Println("Press enter to apply changes.")
-	fmt.Scanln()
+		waves = planWaves(fleet.clusters(), opts.waves)
+		printWaves(waves)
 
-	spin.Suffix(" modifying clusters")
-	spin.Start()
-	updateClustersSecrets(svc, spin)
-	spin.Stop()
+		if err := validatePlan(fleet.clusters(), opts); err != nil {
+			return fmt.Errorf("unable to apply changes: %w", err)
+		}
 
-	return nil
+		fmt.Println("Press enter to apply changes.")
+		fmt.Scanln()
+
+		if lock == nil {
+			lock = newLockInfo(fleet.journal.caller, fleet.journal.runID, opts.lock.ttl, time.Now())
+			if err := fleet.locker.Lock(lock); err != nil {
+				return fmt.Errorf("unable to acquire lock: %w", err)
+			}
+			fleet.heartbeat = startHeartbeat(fleet.locker, lock, opts.lock.ttl, time.Now)
+			defer func() {
+				fleet.heartbeat.Stop()
+				if err := fleet.locker.Unlock(lock); err != nil {
+					fmt.Printf("warning: unable to release lock: %v\n", err)
+				}
+			}()
+		}
+
+		spin.Suffix(" checking for drift")
+		spin.Start()
+		drifts, err := detectDrift(fleet.clusters())
+		if err != nil {
+			spin.StopFail()
+			return fmt.Errorf("unable to check for drift: %w", err)
+		}
+		spin.Stop()
+		fmt.Println()
+
+		if len(drifts) == 0 {
+			break
+		}
+
+		printDrift(drifts)
+		if opts.onDrift == _onDriftAbort {
+			return fmt.Errorf("scram secrets changed on %v clusters since planning", len(drifts))
+		}
+
+		fmt.Println("Scram secrets changed since planning, replanning.")
+		fmt.Println()
+		if err := planFleet(fleet, opts, plan, time.Now()); err != nil {
+			return fmt.Errorf("unable to plan changes: %w", err)
+		}
+	}
+
+	if fleet.checkpoint == nil {
+		fleet.checkpoint = newCheckpoint(opts.checkpoint, fleet.journal.runID, fleet.clusters())
+	}
+	if err := fleet.checkpoint.Save(); err != nil {
+		return fmt.Errorf("unable to save checkpoint: %w", err)
+	}
+
+	err := applyWaves(fleet, waves, opts, plan, spin)
+
+	fmt.Println()
+	printCheckpoint(fleet.checkpoint)
+
+	if err != nil {
+		fmt.Println("Run apply --resume to continue from the checkpoint.")
+		fmt.Println()
+		return err
+	}
+
+	return fleet.checkpoint.Remove()
+}
+
+func loadConfig(opts AWSOptions) (aws.Config, error) {
+	base, err := loadBaseConfig(opts)
+	if err != nil {
+		return base, err
+	}
+
+	return readConfig(base, opts), nil
 }
 
-func newService() (svc *Service, err error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+// loadCallerConfig returns the base config and the caller, resolved before any
+// role is assumed so the operator tag names who ran the change rather than the
+// read role.
+func loadCallerConfig(opts AWSOptions) (aws.Config, string, error) {
+	base, err := loadBaseConfig(opts)
+	if err != nil {
+		return base, "", err
+	}
+
+	caller, err := getCallerIdentity(newSTSClient(base, opts.endpoints))
 	if err != nil {
-		return nil, fmt.Errorf("unable to create aws config: %w", err)
+		return base, "", err
+	}
+
+	return base, caller, nil
+}
+
+// loadBaseConfig loads the credentials of the caller without assuming a role.
+func loadBaseConfig(opts AWSOptions) (aws.Config, error) {
+	optFns := []func(*config.LoadOptions) error{}
+	if opts.profile != "" {
+		optFns = append(optFns, config.WithSharedConfigProfile(opts.profile))
+	}
+	if opts.region != "" {
+		optFns = append(optFns, config.WithRegion(opts.region))
 	}
 
+	cfg, err := config.LoadDefaultConfig(context.TODO(), optFns...)
+	if err != nil {
+		return cfg, fmt.Errorf("unable to create aws config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// readConfig returns the config for reading, assuming the role when one is
+// configured.
+func readConfig(base aws.Config, opts AWSOptions) aws.Config {
+	if opts.roleArn == "" {
+		return base
+	}
+
+	return assumeRole(base, opts, opts.roleArn)
+}
+
+func newService(cfg aws.Config, endpoints Endpoints) *Service {
 	return &Service{
-		kafka:          kafka.NewFromConfig(cfg),
-		secretsmanager: secretsmanager.NewFromConfig(cfg),
-	}, nil
+		region: cfg.Region,
+		kafka: kafka.NewFromConfig(cfg, func(o *kafka.Options) {
+			if endpoints.kafka != "" {
+				o.EndpointResolver = kafka.EndpointResolverFromURL(endpoints.kafka)
+			}
+		}),
+		secretsmanager: secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+			if endpoints.secretsmanager != "" {
+				o.EndpointResolver = secretsmanager.EndpointResolverFromURL(endpoints.secretsmanager)
+			}
+		}),
+	}
 }
 
-func listClustersSecrets(svc *Service) error {
+func listClustersSecrets(svc *Service, opts *Options) error {
 	clusterInfo := make(chan []kafkatypes.ClusterInfo, 1)
 	secretListEntry := make(chan []secretsmanagertypes.SecretListEntry, 1)
 
 	g := new(errgroup.Group)
 
 	g.Go(func() error {
-		ci, err := listClusters(svc.kafka)
+		ci, err := listClusters(svc.kafka, svc.pageSize)
 		if err != nil {
 			return fmt.Errorf("unable to list clusters: %w", err)
 		}
@@ -98,7 +312,7 @@ func listClustersSecrets(svc *Service) error {
 	})
 
 	g.Go(func() error {
-		secrets, err := listSecrets(svc.secretsmanager)
+		secrets, err := listSecrets(svc.secretsmanager, opts.config.SecretFilter, svc.pageSize)
 		if err != nil {
 			return fmt.Errorf("unable to list secrets: %w", err)
 		}
@@ -112,9 +326,14 @@ func listClustersSecrets(svc *Service) error {
 
 	for _, ci := range <-clusterInfo {
 		ci := ci
-		svc.clusters = append(svc.clusters, &Cluster{
+		cluster := &Cluster{
 			clusterInfo: &ci,
-		})
+			service:     svc,
+			ignored:     isIgnoredCluster(ci.Tags),
+			adopt:       opts.adopt,
+		}
+		opts.config.applyCluster(cluster, opts.removals, opts.removalsFlag)
+		svc.clusters = append(svc.clusters, cluster)
 	}
 
 	svc.secrets = <-secretListEntry
@@ -122,25 +341,25 @@ func listClustersSecrets(svc *Service) error {
 	return nil
 }
 
-func listScramSecretsByCluster(svc *Service, spin *yacspin.Spinner) error {
+func listScramSecretsByCluster(clusters []*Cluster, spin *yacspin.Spinner) error {
 	g := new(errgroup.Group)
 
-	clusterName := make(chan string, len(svc.clusters))
+	clusterName := make(chan string, len(clusters))
 
 	go func() {
 		format := "list scram secrets [%v/%v] - %v"
 		spinner.WatchChan(spin, clusterName, format)
 	}()
 
-	for _, cluster := range svc.clusters {
+	for _, cluster := range clusters {
 		cluster := cluster
 		g.Go(func() error {
-			scramSecrets, err := listScramSecrets(svc.kafka, cluster.clusterInfo.ClusterArn)
+			scramSecrets, err := listScramSecrets(cluster.service.kafka, cluster.clusterInfo.ClusterArn, cluster.service.pageSize)
 			if err != nil {
 				return fmt.Errorf("unable to list scram secrets: %w", err)
 			}
 			cluster.assosciatedSecretArnList = scramSecrets
-			clusterName <- aws.ToString(cluster.clusterInfo.ClusterName)
+			clusterName <- clusterLabel(cluster)
 			return nil
 		})
 	}
@@ -154,25 +373,111 @@ func listScramSecretsByCluster(svc *Service, spin *yacspin.Spinner) error {
 	return nil
 }
 
-func updateClustersSecrets(svc *Service, spin *yacspin.Spinner) error {
-	for _, cluster := range svc.clusters {
-		name := aws.ToString(cluster.clusterInfo.ClusterName)
+func updateClustersSecrets(fleet *Fleet, clusters []*Cluster, concurrency int, spin *yacspin.Spinner) error {
+	return forEachCluster(clusters, concurrency, func(cluster *Cluster) error {
+		name := clusterLabel(cluster)
 		spin.Message(fmt.Sprintf("updating scram secrets [%v]", name))
-		if err := associateSecrets(svc.kafka, cluster); err != nil {
-			return fmt.Errorf("unable to assosciate secrets for %v: %w", name, err)
+		if err := updateClusterSecrets(fleet, cluster); err != nil {
+			return fmt.Errorf("unable to update secrets for %v: %w", name, err)
+		}
+		if err := fleet.checkpoint.CompleteCluster(aws.ToString(cluster.clusterInfo.ClusterArn)); err != nil {
+			return fmt.Errorf("unable to record checkpoint: %w", err)
 		}
-		// if err := disassociateSecrets(svc.kafka, cluster); err != nil {
-		// 	return fmt.Errorf("unable to disassosciate secrets for %v: %w", name, err)
-		// }
+		return nil
+	})
+}
+
+func updateClusterSecrets(fleet *Fleet, cluster *Cluster) error {
+	svc := cluster.service
+	kafkaWriter := svc.writeKafka()
+	secretsmanagerWriter := svc.writeSecretsManager()
+
+	if err := tagOwnedSecrets(secretsmanagerWriter, cluster.clusterInfo.ClusterArn, cluster.secretArnChangeSet.adopt); err != nil {
+		return fmt.Errorf("unable to tag adopted secrets: %w", err)
+	}
+
+	// Secrets are tagged before they are associated so a failure can never
+	// leave an association without ownership, which a resumed or later run
+	// would not repair as the secret no longer needs adding.
+	err := updateBatches(cluster.secretArnChangeSet.add, func(batch []string) error {
+		if err := tagOwnedSecrets(secretsmanagerWriter, cluster.clusterInfo.ClusterArn, batch); err != nil {
+			return fmt.Errorf("unable to tag secrets: %w", err)
+		}
+		unprocessed, err := associateSecrets(kafkaWriter, cluster.clusterInfo.ClusterArn, batch)
+		if rerr := recordBatch(fleet, newJournalEntry(cluster, _actionAssociate, batch, unprocessed, err)); rerr != nil {
+			return rerr
+		}
+		if err != nil {
+			return fmt.Errorf("unable to assosciate secrets: %w", err)
+		}
+		if err := untagOwnedSecrets(secretsmanagerWriter, cluster.clusterInfo.ClusterArn, unprocessedSecretArns(unprocessed)); err != nil {
+			return fmt.Errorf("unable to untag unprocessed secrets: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return updateBatches(cluster.secretArnChangeSet.remove, func(batch []string) error {
+		unprocessed, err := disassociateSecrets(kafkaWriter, cluster.clusterInfo.ClusterArn, batch)
+		if rerr := recordBatch(fleet, newJournalEntry(cluster, _actionDisassociate, batch, unprocessed, err)); rerr != nil {
+			return rerr
+		}
+		if err != nil {
+			return fmt.Errorf("unable to disassosciate secrets: %w", err)
+		}
+		processed := sliceutil.Diff(batch, unprocessedSecretArns(unprocessed))
+		if err := untagOwnedSecrets(secretsmanagerWriter, cluster.clusterInfo.ClusterArn, processed); err != nil {
+			return fmt.Errorf("unable to untag disassosciated secrets: %w", err)
+		}
+		return nil
+	})
+}
+
+// updateBatches runs the batches in order and stops at the first failure as
+// batch requests to the same cluster must not overlap.
+func updateBatches(secretArnList []string, update func([]string) error) error {
+	for _, batch := range sliceutil.Chunk(secretArnList, _batchSize) {
+		if err := update(batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func recordBatch(fleet *Fleet, entry *JournalEntry) error {
+	if err := fleet.journal.Record(entry); err != nil {
+		return fmt.Errorf("unable to record journal entry: %w", err)
+	}
+
+	if err := fleet.checkpoint.RecordBatch(entry); err != nil {
+		return fmt.Errorf("unable to record checkpoint: %w", err)
 	}
 
 	return nil
 }
 
-func mapSecretsToClusters(cluster *Cluster, secrets []secretsmanagertypes.SecretListEntry) error {
+func mapSecretsToClusters(cluster *Cluster, secrets []secretsmanagertypes.SecretListEntry, mappers []Mapper) error {
+	cluster.bindings = map[string]string{}
+	cluster.ruleErrors = nil
+
 	for _, secret := range secrets {
-		if isClusterSecret(cluster.clusterInfo.ClusterName, secret.Tags) {
-			cluster.secretArnList = append(cluster.secretArnList, aws.ToString(secret.ARN))
+		arn := aws.ToString(secret.ARN)
+		rule, bound := mapSecret(cluster, secret, mappers)
+		if isIgnoredSecret(secret.Tags) {
+			if bound || sliceutil.Contains(cluster.assosciatedSecretArnList, arn) {
+				cluster.ignoredSecretArnList = append(cluster.ignoredSecretArnList, arn)
+			}
+			continue
+		}
+		if isOwnedSecret(cluster.clusterInfo, secret.Tags) {
+			cluster.ownedSecretArnList = append(cluster.ownedSecretArnList, arn)
+		}
+		if bound {
+			cluster.secretArnList = append(cluster.secretArnList, arn)
+			cluster.bindings[arn] = rule
 			continue
 		}
 	}
@@ -181,26 +486,68 @@ func mapSecretsToClusters(cluster *Cluster, secrets []secretsmanagertypes.Secret
 }
 
 func reconcileClusterSecrets(cluster *Cluster) error {
+	if cluster.ignored {
+		cluster.secretArnChangeSet = &SecretChangeSet{
+			add:       []string{},
+			remove:    []string{},
+			unmanaged: []string{},
+		}
+		return nil
+	}
+
 	add := sliceutil.Diff(cluster.secretArnList, cluster.assosciatedSecretArnList)
-	// remove := sliceutil.Diff(cluster.assosciatedSecretArnList, cluster.secretArnList)
 	remove := []string{}
+	unmanaged := []string{}
+
+	// Adopting tags bound secrets that were associated before ownership was
+	// recorded so later removals can manage them.
+	var adopt []string
+	if cluster.adopt {
+		adopt = sliceutil.Diff(sliceutil.Diff(cluster.secretArnList, add), cluster.ownedSecretArnList)
+	}
+
+	if cluster.removals {
+		stale := sliceutil.Diff(sliceutil.Diff(cluster.assosciatedSecretArnList, cluster.secretArnList), cluster.ignoredSecretArnList)
+		unmanaged = sliceutil.Diff(stale, cluster.ownedSecretArnList)
+		remove = sliceutil.Diff(stale, unmanaged)
+	}
 
 	cluster.secretArnChangeSet = &SecretChangeSet{
-		add:    add,
-		remove: remove,
+		add:       add,
+		remove:    remove,
+		unmanaged: unmanaged,
+		adopt:     adopt,
 	}
 
 	return nil
 }
 
-func isClusterSecret(name *string, tags []secretsmanagertypes.Tag) bool {
+func isClusterSecret(cluster *Cluster, tags []secretsmanagertypes.Tag) bool {
+	_, ok := clusterSecretRule(cluster, tags)
+	return ok
+}
+
+func clusterSecretRule(cluster *Cluster, tags []secretsmanagertypes.Tag) (string, bool) {
+	if isSelectedCluster(cluster, tags) {
+		return "tag " + _clusterSelectorTagKey, true
+	}
+
+	key := clusterTagKey(cluster)
 	for _, tag := range tags {
-		if aws.ToString(tag.Key) == "Cluster" {
-			if strings.HasPrefix(aws.ToString(name), aws.ToString(tag.Value)) {
-				return true
+		if aws.ToString(tag.Key) == key {
+			if strings.HasPrefix(aws.ToString(cluster.clusterInfo.ClusterName), aws.ToString(tag.Value)) {
+				return "tag " + key, true
 			}
 		}
 	}
 
-	return false
+	return "", false
+}
+
+func clusterTagKey(cluster *Cluster) string {
+	if cluster.tagKey == "" {
+		return _clusterTagKey
+	}
+
+	return cluster.tagKey
 }
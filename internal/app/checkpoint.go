@@ -0,0 +1,259 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/theckman/yacspin"
+
+	"github.com/mikelorant/msk-secret-binder/internal/sliceutil"
+)
+
+const (
+	_checkpointFile = "checkpoint.json"
+)
+
+type Checkpoint struct {
+	RunID    string               `json:"run_id"`
+	Started  time.Time            `json:"started"`
+	Clusters []*CheckpointCluster `json:"clusters"`
+
+	path string
+	mu   sync.Mutex
+}
+
+type CheckpointCluster struct {
+	ClusterArn  string          `json:"cluster_arn"`
+	ClusterName string          `json:"cluster_name"`
+	Account     string          `json:"account,omitempty"`
+	Region      string          `json:"region,omitempty"`
+	Add         []string        `json:"add"`
+	Remove      []string        `json:"remove"`
+	Batches     []*JournalEntry `json:"batches"`
+	Completed   bool            `json:"completed"`
+}
+
+func defaultCheckpointPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return _checkpointFile
+	}
+
+	return filepath.Join(home, _journalDir, _checkpointFile)
+}
+
+func newCheckpoint(path, runID string, clusters []*Cluster) *Checkpoint {
+	cp := &Checkpoint{
+		RunID:    runID,
+		Started:  time.Now().UTC(),
+		Clusters: []*CheckpointCluster{},
+		path:     path,
+	}
+
+	for _, cluster := range clusters {
+		if !shouldApply(cluster) {
+			continue
+		}
+		cc := &CheckpointCluster{
+			ClusterArn:  aws.ToString(cluster.clusterInfo.ClusterArn),
+			ClusterName: aws.ToString(cluster.clusterInfo.ClusterName),
+			Add:         cluster.secretArnChangeSet.add,
+			Remove:      cluster.secretArnChangeSet.remove,
+			Batches:     []*JournalEntry{},
+		}
+		if cluster.service != nil {
+			cc.Account = cluster.service.account
+			cc.Region = cluster.service.region
+		}
+		cp.Clusters = append(cp.Clusters, cc)
+	}
+
+	return cp
+}
+
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no checkpoint found at %v", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read checkpoint: %w", err)
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("unable to decode checkpoint: %w", err)
+	}
+	cp.path = path
+
+	return cp, nil
+}
+
+// checkNoCheckpoint refuses to start a new run while the checkpoint of an
+// interrupted run exists, as the new run would overwrite it.
+func checkNoCheckpoint(path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	cp, err := loadCheckpoint(path)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("checkpoint of interrupted run %v exists at %v: run apply --resume to continue it or remove the checkpoint to start over", cp.RunID, path)
+}
+
+func (c *Checkpoint) Save() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.save()
+}
+
+func (c *Checkpoint) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create checkpoint directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode checkpoint: %w", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("unable to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("unable to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Checkpoint) RecordBatch(entry *JournalEntry) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cc := c.cluster(entry.ClusterArn)
+	if cc == nil {
+		return fmt.Errorf("cluster %v not in checkpoint", entry.ClusterArn)
+	}
+	cc.Batches = append(cc.Batches, entry)
+
+	return c.save()
+}
+
+func (c *Checkpoint) CompleteCluster(clusterArn string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cc := c.cluster(clusterArn)
+	if cc == nil {
+		return fmt.Errorf("cluster %v not in checkpoint", clusterArn)
+	}
+	cc.Completed = true
+
+	return c.save()
+}
+
+func (c *Checkpoint) Remove() error {
+	if c == nil {
+		return nil
+	}
+
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to remove checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Checkpoint) cluster(clusterArn string) *CheckpointCluster {
+	for _, cc := range c.Clusters {
+		if cc.ClusterArn == clusterArn {
+			return cc
+		}
+	}
+
+	return nil
+}
+
+// remaining returns the change sets of incomplete clusters with the secrets
+// from successfully processed batches removed.
+func (c *Checkpoint) remaining() map[string]*SecretChangeSet {
+	remaining := map[string]*SecretChangeSet{}
+
+	for _, cc := range c.Clusters {
+		if cc.Completed {
+			continue
+		}
+
+		done := map[string][]string{}
+		for _, batch := range cc.Batches {
+			if batch.Result.Status == _resultFailed {
+				continue
+			}
+			unprocessed := []string{}
+			for _, v := range batch.Result.Unprocessed {
+				unprocessed = append(unprocessed, v.SecretArn)
+			}
+			done[batch.Action] = append(done[batch.Action], sliceutil.Diff(batch.SecretArns, unprocessed)...)
+		}
+
+		remaining[cc.ClusterArn] = &SecretChangeSet{
+			add:    sliceutil.Diff(cc.Add, done[_actionAssociate]),
+			remove: sliceutil.Diff(cc.Remove, done[_actionDisassociate]),
+		}
+	}
+
+	return remaining
+}
+
+func resumeApply(fleet *Fleet, opts *Options, spin *yacspin.Spinner) error {
+	cp, err := loadCheckpoint(opts.checkpoint)
+	if err != nil {
+		return err
+	}
+
+	fleet.checkpoint = cp
+	fleet.journal.runID = cp.RunID
+
+	remaining := cp.remaining()
+
+	fmt.Printf("Resume run %v.\n", cp.RunID)
+	fmt.Println()
+
+	keep := func(cluster *Cluster) bool {
+		_, ok := remaining[aws.ToString(cluster.clusterInfo.ClusterArn)]
+		return ok
+	}
+	if err := retrieveData(fleet, opts, spin, keep); err != nil {
+		return err
+	}
+
+	plan := func(svc *Service, opts *Options, now time.Time) error {
+		return planChangeSets(svc, opts, remaining, now)
+	}
+
+	return confirmAndApply(fleet, opts, spin, plan)
+}
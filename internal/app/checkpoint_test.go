@@ -0,0 +1,138 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCheckpoint(t *testing.T) {
+	clusters := []*Cluster{
+		{
+			clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("example1"), ClusterArn: aws.String("cluster1")},
+			secretArnChangeSet: &SecretChangeSet{
+				add:    []string{"apple"},
+				remove: []string{"pear"},
+			},
+		}, {
+			clusterInfo:        &kafkatypes.ClusterInfo{ClusterName: aws.String("example2"), ClusterArn: aws.String("cluster2")},
+			secretArnChangeSet: &SecretChangeSet{},
+		}, {
+			clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("example3"), ClusterArn: aws.String("cluster3")},
+			secretArnChangeSet: &SecretChangeSet{
+				add: []string{"orange"},
+			},
+			deferred: "outside window",
+		},
+	}
+
+	cp := newCheckpoint("checkpoint.json", "run1", clusters)
+	td.Cmp(t, cp.RunID, "run1")
+	td.Cmp(t, cp.Clusters, []*CheckpointCluster{
+		{
+			ClusterArn:  "cluster1",
+			ClusterName: "example1",
+			Add:         []string{"apple"},
+			Remove:      []string{"pear"},
+			Batches:     []*JournalEntry{},
+		},
+	})
+}
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	cp := &Checkpoint{
+		RunID: "run1",
+		Clusters: []*CheckpointCluster{
+			{ClusterArn: "cluster1", ClusterName: "example1", Add: []string{"apple"}, Batches: []*JournalEntry{}},
+			{ClusterArn: "cluster2", ClusterName: "example2", Add: []string{"pear"}, Batches: []*JournalEntry{}},
+		},
+		path: path,
+	}
+	assert.NoError(t, cp.Save())
+
+	entry := &JournalEntry{
+		ClusterArn: "cluster1",
+		Action:     "associate",
+		SecretArns: []string{"apple"},
+		Result:     JournalResult{Status: "succeeded"},
+	}
+	assert.NoError(t, cp.RecordBatch(entry))
+	assert.NoError(t, cp.CompleteCluster("cluster1"))
+	assert.Error(t, cp.CompleteCluster("cluster3"))
+
+	got, err := loadCheckpoint(path)
+	assert.NoError(t, err)
+	td.Cmp(t, got.RunID, "run1")
+	td.Cmp(t, got.Clusters[0].Completed, true)
+	td.Cmp(t, got.Clusters[0].Batches, []*JournalEntry{entry})
+	td.Cmp(t, got.Clusters[1].Completed, false)
+
+	assert.NoError(t, got.Remove())
+	_, err = loadCheckpoint(path)
+	assert.EqualError(t, err, "no checkpoint found at "+path)
+}
+
+func TestCheckpointRemaining(t *testing.T) {
+	cp := &Checkpoint{
+		Clusters: []*CheckpointCluster{
+			{
+				ClusterArn: "cluster1",
+				Add:        []string{"apple"},
+				Completed:  true,
+			}, {
+				ClusterArn: "cluster2",
+				Add:        []string{"apple", "pear", "orange", "lemon"},
+				Remove:     []string{"peach"},
+				Batches: []*JournalEntry{
+					{
+						Action:     "associate",
+						SecretArns: []string{"apple", "pear"},
+						Result: JournalResult{
+							Status:      "partial",
+							Unprocessed: []JournalUnprocessed{{SecretArn: "pear"}},
+						},
+					}, {
+						Action:     "associate",
+						SecretArns: []string{"orange"},
+						Result:     JournalResult{Status: "failed"},
+					},
+				},
+			}, {
+				ClusterArn: "cluster3",
+				Add:        []string{"coconut"},
+			},
+		},
+	}
+
+	td.Cmp(t, cp.remaining(), map[string]*SecretChangeSet{
+		"cluster2": {
+			add:    []string{"pear", "orange", "lemon"},
+			remove: []string{"peach"},
+		},
+		"cluster3": {
+			add:    []string{"coconut"},
+			remove: []string{},
+		},
+	})
+}
+
+func TestCheckNoCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	assert.NoError(t, checkNoCheckpoint(path))
+
+	cp := newCheckpoint(path, "run1", nil)
+	assert.NoError(t, cp.Save())
+
+	err := checkNoCheckpoint(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checkpoint of interrupted run run1 exists")
+
+	assert.NoError(t, cp.Remove())
+	assert.NoError(t, checkNoCheckpoint(path))
+}
@@ -0,0 +1,309 @@
+package app
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/theckman/yacspin"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	_configFile      = "config.yaml"
+	_configEnvPrefix = "MSK_SECRET_BINDER_"
+	_maxPageSize     = 100
+)
+
+//go:embed config.schema.json
+var _configSchema []byte
+
+type Config struct {
+	TagKey         string          `yaml:"tagKey"`
+	SecretFilter   string          `yaml:"secretFilter"`
+	PageSize       int32           `yaml:"pageSize"`
+	Removals       bool            `yaml:"removals"`
+	Concurrency    int             `yaml:"concurrency"`
+	Protected      []string        `yaml:"protected"`
+	NamingPattern  string          `yaml:"namingPattern"`
+	UserListTagKey string          `yaml:"userListTagKey"`
+	Rules          []RuleConfig    `yaml:"rules"`
+	Plugin         *PluginConfig   `yaml:"plugin"`
+	Spinner        SpinnerConfig   `yaml:"spinner"`
+	Clusters       []ClusterConfig `yaml:"clusters"`
+
+	rules []*Rule
+}
+
+type SpinnerConfig struct {
+	Frequency time.Duration `yaml:"frequency"`
+	CharSet   int           `yaml:"charSet"`
+}
+
+// ClusterConfig overrides the global settings for clusters with names matching
+// the pattern. The first matching override is used.
+type ClusterConfig struct {
+	Name        string   `yaml:"name"`
+	TagKey      string   `yaml:"tagKey"`
+	Removals    *bool    `yaml:"removals"`
+	Concurrency int      `yaml:"concurrency"`
+	Protected   []string `yaml:"protected"`
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		TagKey:         _clusterTagKey,
+		SecretFilter:   _filterValue,
+		UserListTagKey: _userListTagKey,
+		PageSize:       _maxPageSize,
+		Concurrency:    1,
+		Spinner: SpinnerConfig{
+			Frequency: 50 * time.Millisecond,
+			CharSet:   14,
+		},
+	}
+}
+
+func defaultConfigPath() string {
+	if path, ok := os.LookupEnv(_configEnvPrefix + "CONFIG"); ok {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return _configFile
+	}
+
+	return filepath.Join(home, _journalDir, _configFile)
+}
+
+// loadConfigFile reads the config file over the defaults and applies any
+// environment overrides. A missing file is only an error when it was
+// explicitly requested.
+func loadConfigFile(path string, required bool) (*Config, error) {
+	conf := defaultConfig()
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !required:
+	case err != nil:
+		return nil, fmt.Errorf("unable to read config: %w", err)
+	default:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(conf); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("unable to parse config %v: %w", path, err)
+		}
+	}
+
+	conf.setDefaults()
+
+	if err := conf.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := conf.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %v: %w", path, err)
+	}
+
+	conf.rules, err = newRules(conf.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %v: %w", path, err)
+	}
+
+	return conf, nil
+}
+
+// setDefaults fills the defaults of optional sections, which are only known to
+// be present after decoding.
+func (c *Config) setDefaults() {
+	if c.Plugin != nil {
+		if c.Plugin.Mode == "" {
+			c.Plugin.Mode = _pluginModeAugment
+		}
+		if c.Plugin.Timeout == 0 {
+			c.Plugin.Timeout = _pluginTimeout
+		}
+	}
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	env := func(name string) (string, bool) {
+		return lookup(_configEnvPrefix + name)
+	}
+
+	if v, ok := env("TAG_KEY"); ok {
+		c.TagKey = v
+	}
+	if v, ok := env("SECRET_FILTER"); ok {
+		c.SecretFilter = v
+	}
+	if v, ok := env("NAMING_PATTERN"); ok {
+		c.NamingPattern = v
+	}
+	if v, ok := env("USER_LIST_TAG_KEY"); ok {
+		c.UserListTagKey = v
+	}
+	if v, ok := env("PAGE_SIZE"); ok {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid %vPAGE_SIZE: %w", _configEnvPrefix, err)
+		}
+		c.PageSize = int32(n)
+	}
+	if v, ok := env("REMOVALS"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %vREMOVALS: %w", _configEnvPrefix, err)
+		}
+		c.Removals = b
+	}
+	if v, ok := env("CONCURRENCY"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid %vCONCURRENCY: %w", _configEnvPrefix, err)
+		}
+		c.Concurrency = n
+	}
+	if v, ok := env("SPINNER_FREQUENCY"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %vSPINNER_FREQUENCY: %w", _configEnvPrefix, err)
+		}
+		c.Spinner.Frequency = d
+	}
+	if v, ok := env("SPINNER_CHARSET"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid %vSPINNER_CHARSET: %w", _configEnvPrefix, err)
+		}
+		c.Spinner.CharSet = n
+	}
+
+	return nil
+}
+
+func (c *Config) validate() error {
+	if c.TagKey == "" {
+		return fmt.Errorf("tag key must not be empty")
+	}
+	if c.PageSize < 1 || c.PageSize > _maxPageSize {
+		return fmt.Errorf("page size %v must be between 1 and %v", c.PageSize, _maxPageSize)
+	}
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency %v must be at least 1", c.Concurrency)
+	}
+	if c.Spinner.Frequency <= 0 {
+		return fmt.Errorf("spinner frequency must be positive")
+	}
+	if _, ok := yacspin.CharSets[c.Spinner.CharSet]; !ok {
+		return fmt.Errorf("unknown spinner character set: %v", c.Spinner.CharSet)
+	}
+
+	if err := validateProtectedPatterns(c.Protected); err != nil {
+		return err
+	}
+
+	if c.NamingPattern != "" {
+		if _, err := newNamingMapper(c.NamingPattern); err != nil {
+			return err
+		}
+	}
+
+	if c.Plugin != nil {
+		if err := c.Plugin.validate(); err != nil {
+			return err
+		}
+	}
+
+	for i, cluster := range c.Clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("cluster override %v: name must not be empty", i+1)
+		}
+		if _, err := path.Match(cluster.Name, ""); err != nil {
+			return fmt.Errorf("cluster override %v: invalid name pattern %q: %w", i+1, cluster.Name, err)
+		}
+		if cluster.Concurrency < 0 {
+			return fmt.Errorf("cluster override %v: concurrency %v must not be negative", i+1, cluster.Concurrency)
+		}
+		if err := validateProtectedPatterns(cluster.Protected); err != nil {
+			return fmt.Errorf("cluster override %v: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// applyCluster sets the cluster settings from the global settings and the
+// first override matching the cluster name. An explicit --remove flag wins
+// over the removals of the override.
+func (c *Config) applyCluster(cluster *Cluster, removals, removalsFlag bool) {
+	cluster.tagKey = c.TagKey
+	cluster.removals = removals
+
+	name := aws.ToString(cluster.clusterInfo.ClusterName)
+	for _, override := range c.Clusters {
+		if ok, _ := path.Match(override.Name, name); !ok {
+			continue
+		}
+		if override.TagKey != "" {
+			cluster.tagKey = override.TagKey
+		}
+		if override.Removals != nil && !removalsFlag {
+			cluster.removals = *override.Removals
+		}
+		cluster.concurrencyGroup = override.Name
+		cluster.concurrency = override.Concurrency
+		cluster.protected = override.Protected
+		return
+	}
+}
+
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected config subcommand: validate or schema")
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "validate":
+		return runConfigValidate(args)
+	case "schema":
+		fmt.Print(string(_configSchema))
+		return nil
+	}
+
+	return fmt.Errorf("unknown config subcommand: %v", command)
+}
+
+func runConfigValidate(args []string) error {
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	path := fs.String("config", defaultConfigPath(), "path to the config file")
+	schema := fs.Bool("schema", false, "print the config JSON Schema")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("unable to parse flags: %w", err)
+	}
+
+	if *schema {
+		fmt.Print(string(_configSchema))
+		return nil
+	}
+
+	if _, err := loadConfigFile(*path, true); err != nil {
+		return err
+	}
+
+	fmt.Printf("%v is valid.\n", *path)
+
+	return nil
+}
@@ -0,0 +1,300 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		give     string
+		giveEnv  map[string]string
+		missing  bool
+		required bool
+		want     *Config
+		wantErr  bool
+	}{
+		{
+			name:    "missing",
+			missing: true,
+			want:    defaultConfig(),
+		}, {
+			name:     "missing_required",
+			missing:  true,
+			required: true,
+			wantErr:  true,
+		}, {
+			name: "empty",
+			give: "",
+			want: defaultConfig(),
+		}, {
+			name: "global",
+			give: "tagKey: Team\npageSize: 50\nremovals: true\nspinner:\n  frequency: 100ms\n",
+			want: func() *Config {
+				c := defaultConfig()
+				c.TagKey = "Team"
+				c.PageSize = 50
+				c.Removals = true
+				c.Spinner.Frequency = 100 * time.Millisecond
+				return c
+			}(),
+		}, {
+			name: "clusters",
+			give: "clusters:\n  - name: prod-*\n    removals: false\n    concurrency: 2\n",
+			want: func() *Config {
+				c := defaultConfig()
+				c.Clusters = []ClusterConfig{
+					{Name: "prod-*", Removals: aws.Bool(false), Concurrency: 2},
+				}
+				return c
+			}(),
+		}, {
+			name:    "env",
+			give:    "tagKey: Team\n",
+			giveEnv: map[string]string{"MSK_SECRET_BINDER_TAG_KEY": "Owner", "MSK_SECRET_BINDER_CONCURRENCY": "4"},
+			want: func() *Config {
+				c := defaultConfig()
+				c.TagKey = "Owner"
+				c.Concurrency = 4
+				return c
+			}(),
+		}, {
+			name: "plugin",
+			give: "plugin:\n  command: [catalog-matcher, --env, prod]\n",
+			want: func() *Config {
+				c := defaultConfig()
+				c.Plugin = &PluginConfig{
+					Command: []string{"catalog-matcher", "--env", "prod"},
+					Mode:    _pluginModeAugment,
+					Timeout: _pluginTimeout,
+				}
+				return c
+			}(),
+		}, {
+			name:    "plugin_mode",
+			give:    "plugin:\n  command: [catalog-matcher]\n  mode: merge\n",
+			wantErr: true,
+		}, {
+			name:    "invalid_env",
+			giveEnv: map[string]string{"MSK_SECRET_BINDER_PAGE_SIZE": "many"},
+			wantErr: true,
+		}, {
+			name:    "unknown_field",
+			give:    "tagkey: Team\n",
+			wantErr: true,
+		}, {
+			name:    "page_size",
+			give:    "pageSize: 500\n",
+			wantErr: true,
+		}, {
+			name:    "charset",
+			give:    "spinner:\n  charSet: 1000\n",
+			wantErr: true,
+		}, {
+			name:    "cluster_name",
+			give:    "clusters:\n  - removals: true\n",
+			wantErr: true,
+		}, {
+			name:    "protected_pattern",
+			give:    "protected: [\"AmazonMSK_[\"]\n",
+			wantErr: true,
+		}, {
+			name:    "cluster_protected_pattern",
+			give:    "clusters:\n  - name: prod-*\n    protected: [\"AmazonMSK_[\"]\n",
+			wantErr: true,
+		}, {
+			name:    "rule",
+			give:    "rules:\n  - name: prod\n    action: bind\n    expression: cluster.name ==\n",
+			wantErr: true,
+		}, {
+			name:    "cluster_pattern",
+			give:    "clusters:\n  - name: \"[\"\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.giveEnv {
+				t.Setenv(k, v)
+			}
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if !tt.missing {
+				assert.NoError(t, os.WriteFile(path, []byte(tt.give), 0o600))
+			}
+
+			got, err := loadConfigFile(path, tt.required)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestLoadConfigFileRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	give := "rules:\n  - name: prod\n    action: veto\n    expression: cluster.name.endsWith('-prd')\n"
+	assert.NoError(t, os.WriteFile(path, []byte(give), 0o600))
+
+	got, err := loadConfigFile(path, true)
+	if !assert.Nil(t, err) || !assert.Len(t, got.rules, 1) {
+		return
+	}
+	td.Cmp(t, got.rules[0].name, "prod")
+	td.Cmp(t, got.rules[0].action, _ruleActionVeto)
+}
+
+func TestApplyCluster(t *testing.T) {
+	conf := defaultConfig()
+	conf.Clusters = []ClusterConfig{
+		{Name: "prod-*", TagKey: "Team", Removals: aws.Bool(false), Concurrency: 3, Protected: []string{"admin"}},
+		{Name: "prod-payments", TagKey: "Owner"},
+	}
+
+	tests := []struct {
+		name            string
+		give            string
+		giveFlag        bool
+		wantTagKey      string
+		wantRemovals    bool
+		wantGroup       string
+		wantConcurrency int
+		wantProtected   []string
+	}{
+		{
+			name:            "global",
+			give:            "dev-payments",
+			wantTagKey:      "Cluster",
+			wantRemovals:    true,
+			wantConcurrency: 0,
+		}, {
+			name:            "first_match",
+			give:            "prod-payments",
+			wantTagKey:      "Team",
+			wantRemovals:    false,
+			wantGroup:       "prod-*",
+			wantConcurrency: 3,
+			wantProtected:   []string{"admin"},
+		}, {
+			name:            "remove_flag_wins",
+			give:            "prod-payments",
+			giveFlag:        true,
+			wantTagKey:      "Team",
+			wantRemovals:    true,
+			wantGroup:       "prod-*",
+			wantConcurrency: 3,
+			wantProtected:   []string{"admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &Cluster{
+				clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String(tt.give)},
+			}
+			conf.applyCluster(cluster, true, tt.giveFlag)
+
+			assert.Equal(t, tt.wantTagKey, cluster.tagKey)
+			assert.Equal(t, tt.wantRemovals, cluster.removals)
+			assert.Equal(t, tt.wantGroup, cluster.concurrencyGroup)
+			assert.Equal(t, tt.wantConcurrency, cluster.concurrency)
+			td.Cmp(t, cluster.protected, tt.wantProtected)
+		})
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	var schema map[string]interface{}
+	assert.Nil(t, json.Unmarshal(_configSchema, &schema))
+	assert.Contains(t, schema, "properties")
+
+	compareSchema(t, "config", schema, reflect.ValueOf(*defaultConfig()), true)
+}
+
+// compareSchema checks that the schema properties match the yaml fields of the
+// struct and, where the Go value holds the defaults, that the defaults match.
+func compareSchema(t *testing.T, path string, schema map[string]interface{}, value reflect.Value, defaults bool) {
+	t.Helper()
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			compareSchema(t, path, schema, reflect.New(value.Type().Elem()).Elem(), false)
+		} else {
+			compareSchema(t, path, schema, value.Elem(), defaults)
+		}
+		return
+	case reflect.Slice:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			compareSchema(t, path+"[]", items, reflect.New(value.Type().Elem()).Elem(), false)
+		}
+		return
+	case reflect.Struct:
+	default:
+		if def, ok := schema["default"]; ok && defaults {
+			td.Cmp(t, fmt.Sprint(value.Interface()), fmt.Sprint(def), "default of %v", path)
+		}
+		return
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	assert.Equal(t, false, schema["additionalProperties"], "additional properties of %v", path)
+
+	fields := map[string]bool{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Tag.Get("yaml")
+		fields[name] = true
+
+		property, ok := properties[name].(map[string]interface{})
+		if !assert.True(t, ok, "%v.%v is missing from the schema", path, name) {
+			continue
+		}
+		compareSchema(t, path+"."+name, property, value.Field(i), defaults)
+	}
+
+	for name := range properties {
+		assert.True(t, fields[name], "%v.%v is missing from the config", path, name)
+	}
+}
+
+func TestValidatePluginDefaults(t *testing.T) {
+	conf := defaultConfig()
+	conf.Plugin = &PluginConfig{Command: []string{"catalog-matcher"}}
+	assert.Error(t, conf.validate())
+	td.Cmp(t, conf.Plugin, &PluginConfig{Command: []string{"catalog-matcher"}})
+
+	conf.setDefaults()
+	assert.NoError(t, conf.validate())
+	td.Cmp(t, conf.Plugin, &PluginConfig{Command: []string{"catalog-matcher"}, Mode: _pluginModeAugment, Timeout: _pluginTimeout})
+}
+
+func TestValidateClusterOverrideDefaults(t *testing.T) {
+	conf := defaultConfig()
+	conf.Clusters = []ClusterConfig{{Name: "prod-*"}}
+	assert.NoError(t, conf.validate())
+
+	conf.Clusters[0].Concurrency = -1
+	assert.Error(t, conf.validate())
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+const (
+	_userListTagKey     = "msk-secret-binder:users"
+	_declaredRulePrefix = "declared by "
+	_declaredPrefix     = "prefix:"
+)
+
+// declaredMapper binds secrets listed on the cluster itself. The tag value
+// holds space separated secret names, or "prefix:" followed by the start of
+// the names to bind. Tag values cannot hold commas or glob characters, and
+// secret names cannot hold colons, so the prefix form is never ambiguous.
+type declaredMapper struct {
+	tagKey string
+}
+
+func (m declaredMapper) Map(cluster *Cluster, secret secretsmanagertypes.SecretListEntry) (string, bool) {
+	name := aws.ToString(secret.Name)
+	for _, pattern := range declaredUsers(cluster.clusterInfo.Tags[m.tagKey]) {
+		if ok, _ := matchDeclared(pattern, name); ok {
+			return _declaredRulePrefix + m.tagKey, true
+		}
+	}
+
+	return "", false
+}
+
+func declaredUsers(value string) []string {
+	return strings.Fields(value)
+}
+
+func matchDeclared(pattern, name string) (bool, error) {
+	if strings.HasPrefix(pattern, _declaredPrefix) {
+		prefix := strings.TrimPrefix(pattern, _declaredPrefix)
+		if prefix == "" {
+			return false, fmt.Errorf("empty prefix")
+		}
+		return strings.HasPrefix(name, prefix), nil
+	}
+
+	if kind, _, ok := strings.Cut(pattern, ":"); ok {
+		return false, fmt.Errorf("unknown form %v:", kind)
+	}
+
+	return pattern == name, nil
+}
+
+// DeclarationProblem is a declared user pattern that can never bind a secret.
+type DeclarationProblem struct {
+	cluster *Cluster
+	pattern string
+	problem string
+}
+
+// findDeclarationProblems reports declared patterns that are malformed or
+// match none of the secrets, as the mapper silently skips both.
+func findDeclarationProblems(clusters []*Cluster, secrets []secretsmanagertypes.SecretListEntry, tagKey string) []*DeclarationProblem {
+	problems := []*DeclarationProblem{}
+	if tagKey == "" {
+		return problems
+	}
+
+	for _, cluster := range clusters {
+		for _, pattern := range declaredUsers(cluster.clusterInfo.Tags[tagKey]) {
+			problem, ok := checkDeclaredPattern(pattern, secrets)
+			if ok {
+				continue
+			}
+			problems = append(problems, &DeclarationProblem{
+				cluster: cluster,
+				pattern: pattern,
+				problem: problem,
+			})
+		}
+	}
+
+	return problems
+}
+
+func checkDeclaredPattern(pattern string, secrets []secretsmanagertypes.SecretListEntry) (string, bool) {
+	if _, err := matchDeclared(pattern, ""); err != nil {
+		return fmt.Sprintf("malformed pattern: %v", err), false
+	}
+
+	for _, secret := range secrets {
+		if ok, _ := matchDeclared(pattern, aws.ToString(secret.Name)); ok {
+			return "", true
+		}
+	}
+
+	return "matches no secret", false
+}
+
+// Conflict is a secret declared by some clusters while its own mapping binds
+// it to different clusters.
+type Conflict struct {
+	name       string
+	declaredBy []string
+	mappedTo   []string
+}
+
+// findConflicts compares cluster declarations with secret-side mappings. Both
+// bindings are applied, so a conflict usually means one side is stale.
+func findConflicts(clusters []*Cluster) []*Conflict {
+	declaredBy := map[string][]string{}
+	mappedTo := map[string][]string{}
+
+	for _, cluster := range clusters {
+		name := clusterLabel(cluster)
+		for arn, rule := range cluster.bindings {
+			if strings.HasPrefix(rule, _declaredRulePrefix) {
+				declaredBy[arn] = append(declaredBy[arn], name)
+				continue
+			}
+			mappedTo[arn] = append(mappedTo[arn], name)
+		}
+	}
+
+	conflicts := []*Conflict{}
+	for arn, declared := range declaredBy {
+		mapped, ok := mappedTo[arn]
+		if !ok {
+			continue
+		}
+		sort.Strings(declared)
+		sort.Strings(mapped)
+		conflicts = append(conflicts, &Conflict{
+			name:       secretName(arn),
+			declaredBy: declared,
+			mappedTo:   mapped,
+		})
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].name < conflicts[j].name
+	})
+
+	return conflicts
+}
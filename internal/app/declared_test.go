@@ -0,0 +1,161 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeclaredMapper(t *testing.T) {
+	tests := []struct {
+		name     string
+		giveTags map[string]string
+		give     string
+		want     bool
+	}{
+		{
+			name:     "exact",
+			giveTags: map[string]string{_userListTagKey: "AmazonMSK_alice AmazonMSK_bob"},
+			give:     "AmazonMSK_bob",
+			want:     true,
+		}, {
+			name:     "prefix",
+			giveTags: map[string]string{_userListTagKey: "AmazonMSK_alice prefix:AmazonMSK_payments/"},
+			give:     "AmazonMSK_payments/carol",
+			want:     true,
+		}, {
+			name:     "prefix_not_matching",
+			giveTags: map[string]string{_userListTagKey: "prefix:AmazonMSK_payments/"},
+			give:     "AmazonMSK_orders/carol",
+		}, {
+			name:     "exact_is_not_prefix",
+			giveTags: map[string]string{_userListTagKey: "AmazonMSK_alice"},
+			give:     "AmazonMSK_alice-old",
+		}, {
+			name:     "not_listed",
+			giveTags: map[string]string{_userListTagKey: "AmazonMSK_alice"},
+			give:     "AmazonMSK_bob",
+		}, {
+			name: "untagged",
+			give: "AmazonMSK_bob",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &Cluster{
+				clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("payments"), Tags: tt.giveTags},
+			}
+			secret := secretsmanagertypes.SecretListEntry{Name: aws.String(tt.give)}
+
+			rule, ok := declaredMapper{tagKey: _userListTagKey}.Map(cluster, secret)
+			assert.Equal(t, tt.want, ok)
+			if tt.want {
+				assert.Equal(t, "declared by "+_userListTagKey, rule)
+			}
+		})
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	mappers := []Mapper{tagMapper{}, declaredMapper{tagKey: _userListTagKey}}
+
+	secrets := []secretsmanagertypes.SecretListEntry{
+		{
+			Name: aws.String("AmazonMSK_alice"),
+			ARN:  aws.String("arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_alice-AbCdEf"),
+			Tags: []secretsmanagertypes.Tag{
+				{Key: aws.String("Cluster"), Value: aws.String("orders")},
+			},
+		}, {
+			Name: aws.String("AmazonMSK_bob"),
+			ARN:  aws.String("arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_bob-AbCdEf"),
+			Tags: []secretsmanagertypes.Tag{
+				{Key: aws.String("Cluster"), Value: aws.String("payments")},
+			},
+		}, {
+			Name: aws.String("AmazonMSK_carol"),
+			ARN:  aws.String("arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_carol-AbCdEf"),
+		},
+	}
+
+	svc := &Service{account: "111111111111", region: "us-east-1"}
+	payments := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("payments"),
+			Tags:        map[string]string{_userListTagKey: "AmazonMSK_alice AmazonMSK_bob AmazonMSK_carol"},
+		},
+		service: svc,
+	}
+	orders := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("orders")},
+		service:     svc,
+	}
+
+	clusters := []*Cluster{payments, orders}
+	for _, cluster := range clusters {
+		mapSecretsToClusters(cluster, secrets, mappers)
+	}
+
+	td.Cmp(t, payments.bindings, map[string]string{
+		"arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_alice-AbCdEf": "declared by " + _userListTagKey,
+		"arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_bob-AbCdEf":   "tag Cluster",
+		"arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_carol-AbCdEf": "declared by " + _userListTagKey,
+	})
+
+	td.Cmp(t, findConflicts(clusters), []*Conflict{
+		{
+			name:       "AmazonMSK_alice",
+			declaredBy: []string{"payments (111111111111/us-east-1)"},
+			mappedTo:   []string{"orders (111111111111/us-east-1)"},
+		},
+	})
+}
+
+func TestFindDeclarationProblems(t *testing.T) {
+	payments := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("payments"),
+			Tags:        map[string]string{_userListTagKey: "AmazonMSK_alice prefix:AmazonMSK_payments_ prefix: suffix:_admin AmazonMSK_zed"},
+		},
+	}
+	orders := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("orders")},
+	}
+
+	secrets := []secretsmanagertypes.SecretListEntry{
+		{Name: aws.String("AmazonMSK_alice")},
+		{Name: aws.String("AmazonMSK_payments_bob")},
+	}
+
+	tests := []struct {
+		name   string
+		tagKey string
+		want   []*DeclarationProblem
+	}{
+		{
+			name:   "problems",
+			tagKey: _userListTagKey,
+			want: []*DeclarationProblem{
+				{cluster: payments, pattern: "prefix:", problem: "malformed pattern: empty prefix"},
+				{cluster: payments, pattern: "suffix:_admin", problem: "malformed pattern: unknown form suffix:"},
+				{cluster: payments, pattern: "AmazonMSK_zed", problem: "matches no secret"},
+			},
+		}, {
+			name: "disabled",
+			want: []*DeclarationProblem{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, findDeclarationProblems([]*Cluster{payments, orders}, secrets, tt.tagKey), tt.want)
+		})
+	}
+}
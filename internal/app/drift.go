@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/theckman/yacspin"
+
+	"github.com/mikelorant/msk-secret-binder/internal/sliceutil"
+)
+
+const (
+	_onDriftReplan = "replan"
+	_onDriftAbort  = "abort"
+)
+
+type Drift struct {
+	cluster *Cluster
+	added   []string
+	removed []string
+}
+
+func detectDrift(clusters []*Cluster) (drifts []*Drift, err error) {
+	drifts = []*Drift{}
+
+	for _, cluster := range clusters {
+		if !shouldApply(cluster) {
+			continue
+		}
+
+		scramSecrets, err := listScramSecrets(cluster.service.kafka, cluster.clusterInfo.ClusterArn, cluster.service.pageSize)
+		if err != nil {
+			return drifts, fmt.Errorf("unable to list scram secrets for %v: %w", clusterLabel(cluster), err)
+		}
+
+		added := sliceutil.Diff(scramSecrets, cluster.assosciatedSecretArnList)
+		removed := sliceutil.Diff(cluster.assosciatedSecretArnList, scramSecrets)
+		if len(added)+len(removed) == 0 {
+			continue
+		}
+
+		cluster.assosciatedSecretArnList = scramSecrets
+		drifts = append(drifts, &Drift{
+			cluster: cluster,
+			added:   added,
+			removed: removed,
+		})
+	}
+
+	return drifts, nil
+}
+
+// checkWaveDrift checks the wave clusters again before they are applied as
+// earlier waves and soaking can leave the plan hours old.
+func checkWaveDrift(wave *Wave, opts *Options, plan planFunc, spin *yacspin.Spinner) error {
+	spin.Suffix(fmt.Sprintf(" checking for drift [wave %v]", wave.name))
+	spin.Start()
+	drifts, err := replanDrift(wave.clusters, opts, plan, time.Now())
+	if err != nil && len(drifts) == 0 {
+		spin.StopFail()
+		return fmt.Errorf("unable to check wave %v for drift: %w", wave.name, err)
+	}
+	spin.Stop()
+	fmt.Println()
+
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	printDrift(drifts)
+	if err != nil {
+		return fmt.Errorf("unable to apply wave %v: %w", wave.name, err)
+	}
+
+	fmt.Printf("Scram secrets changed since planning, replanned wave %v.\n", wave.name)
+	fmt.Println()
+
+	clusters := []*Cluster{}
+	for _, d := range drifts {
+		clusters = append(clusters, d.cluster)
+	}
+	printChangeSet(clusters)
+
+	if err := validatePlan(wave.clusters, opts); err != nil {
+		return fmt.Errorf("unable to apply wave %v: %w", wave.name, err)
+	}
+
+	return nil
+}
+
+// replanDrift replans the clusters whose associations changed since planning
+// or returns an error with the drifts when drift should abort the run.
+func replanDrift(clusters []*Cluster, opts *Options, plan planFunc, now time.Time) ([]*Drift, error) {
+	drifts, err := detectDrift(clusters)
+	if err != nil || len(drifts) == 0 {
+		return nil, err
+	}
+
+	if opts.onDrift == _onDriftAbort {
+		return drifts, fmt.Errorf("scram secrets changed on %v clusters since planning", len(drifts))
+	}
+
+	drifted := []*Cluster{}
+	for _, d := range drifts {
+		drifted = append(drifted, d.cluster)
+	}
+
+	return drifts, replanClusters(drifted, opts, plan, now)
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectDrift(t *testing.T) {
+	tests := []struct {
+		name         string
+		give         []string
+		giveDeferred string
+		want         []*Drift
+	}{
+		{
+			name: "unchanged",
+			give: []string{"apple", "orange"},
+			want: []*Drift{},
+		}, {
+			name: "associated",
+			give: []string{"apple", "orange", "lemon"},
+			want: []*Drift{
+				{added: []string{"lemon"}, removed: []string{}},
+			},
+		}, {
+			name: "disassociated",
+			give: []string{"apple"},
+			want: []*Drift{
+				{added: []string{}, removed: []string{"orange"}},
+			},
+		}, {
+			name:         "deferred",
+			give:         []string{"apple"},
+			giveDeferred: "outside window",
+			want:         []*Drift{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := &mockKafkaClientAPI{
+				listScramSecretsOutput: []*kafka.ListScramSecretsOutput{
+					{SecretArnList: tt.give},
+				},
+			}
+
+			cluster := &Cluster{
+				clusterInfo: &kafkatypes.ClusterInfo{
+					ClusterName: aws.String("example1"),
+					ClusterArn:  aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/1"),
+				},
+				assosciatedSecretArnList: []string{"apple", "orange"},
+				secretArnChangeSet: &SecretChangeSet{
+					add: []string{"pear"},
+				},
+				service:  &Service{kafka: cl},
+				deferred: tt.giveDeferred,
+			}
+
+			got, err := detectDrift([]*Cluster{cluster})
+			assert.NoError(t, err)
+			for _, d := range tt.want {
+				d.cluster = cluster
+			}
+			td.Cmp(t, got, tt.want)
+			if len(tt.want) > 0 {
+				td.Cmp(t, cluster.assosciatedSecretArnList, tt.give)
+			}
+		})
+	}
+}
+
+func TestReplanDrift(t *testing.T) {
+	tests := []struct {
+		name        string
+		giveOnDrift string
+		wantPlanned []string
+		wantErr     bool
+	}{
+		{
+			name:        "replan",
+			giveOnDrift: _onDriftReplan,
+			wantPlanned: []string{"example1"},
+		}, {
+			name:        "abort",
+			giveOnDrift: _onDriftAbort,
+			wantPlanned: []string{},
+			wantErr:     true,
+		},
+	}
+
+	cluster := func(name string, live []string) *Cluster {
+		return &Cluster{
+			clusterInfo: &kafkatypes.ClusterInfo{
+				ClusterName: aws.String(name),
+				ClusterArn:  aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/" + name + "/1"),
+			},
+			assosciatedSecretArnList: []string{"apple"},
+			secretArnChangeSet:       &SecretChangeSet{add: []string{"pear"}},
+			service: &Service{kafka: &mockKafkaClientAPI{
+				listScramSecretsOutput: []*kafka.ListScramSecretsOutput{{SecretArnList: live}},
+			}},
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drifted := cluster("example1", []string{"apple", "lemon"})
+			unchanged := cluster("example2", []string{"apple"})
+
+			planned := []string{}
+			plan := func(svc *Service, opts *Options, now time.Time) error {
+				for _, c := range svc.clusters {
+					planned = append(planned, aws.ToString(c.clusterInfo.ClusterName))
+				}
+				return nil
+			}
+
+			drifts, err := replanDrift([]*Cluster{drifted, unchanged}, &Options{onDrift: tt.giveOnDrift}, plan, time.Now())
+			td.Cmp(t, err != nil, tt.wantErr)
+			td.Cmp(t, drifts, []*Drift{{cluster: drifted, added: []string{"lemon"}, removed: []string{}}})
+			td.Cmp(t, planned, tt.wantPlanned)
+		})
+	}
+}
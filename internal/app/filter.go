@@ -0,0 +1,177 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+type Filters struct {
+	clusters        patternSlice
+	excludeClusters patternSlice
+	secrets         patternSlice
+	excludeSecrets  patternSlice
+	clusterTags     clusterTagSlice
+}
+
+// Pattern matches names using a glob, or a regular expression when the value
+// is wrapped in slashes such as /^prod-.*$/.
+type Pattern struct {
+	value string
+	re    *regexp.Regexp
+}
+
+type patternSlice []*Pattern
+
+func (p *patternSlice) String() string {
+	values := []string{}
+	for _, pattern := range *p {
+		values = append(values, pattern.value)
+	}
+	return strings.Join(values, ",")
+}
+
+func (p *patternSlice) Set(value string) error {
+	pattern, err := parsePattern(value)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, pattern)
+	return nil
+}
+
+type ClusterTag struct {
+	key   string
+	value string
+}
+
+type clusterTagSlice []ClusterTag
+
+func (c *clusterTagSlice) String() string {
+	values := []string{}
+	for _, tag := range *c {
+		values = append(values, tag.key+"="+tag.value)
+	}
+	return strings.Join(values, ",")
+}
+
+func (c *clusterTagSlice) Set(value string) error {
+	key, v, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid cluster tag %q: expected key=value", value)
+	}
+	*c = append(*c, ClusterTag{key: key, value: v})
+	return nil
+}
+
+func parsePattern(value string) (*Pattern, error) {
+	if len(value) > 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+		re, err := regexp.Compile(value[1 : len(value)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", value, err)
+		}
+		return &Pattern{value: value, re: re}, nil
+	}
+
+	if _, err := path.Match(value, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", value, err)
+	}
+
+	return &Pattern{value: value}, nil
+}
+
+func (p *Pattern) Match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+
+	ok, _ := path.Match(p.value, name)
+	return ok
+}
+
+func matchPatterns(name string, include, exclude []*Pattern) bool {
+	for _, pattern := range exclude {
+		if pattern.Match(name) {
+			return false
+		}
+	}
+
+	if len(include) == 0 {
+		return true
+	}
+
+	for _, pattern := range include {
+		if pattern.Match(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Filters) matchCluster(cluster *Cluster) bool {
+	for _, tag := range f.clusterTags {
+		if v, ok := cluster.clusterInfo.Tags[tag.key]; !ok || v != tag.value {
+			return false
+		}
+	}
+
+	return matchPatterns(aws.ToString(cluster.clusterInfo.ClusterName), f.clusters, f.excludeClusters)
+}
+
+func (f *Filters) matchSecret(name string) bool {
+	return matchPatterns(name, f.secrets, f.excludeSecrets)
+}
+
+// filterService narrows the listed clusters and secrets to the selected
+// subset. The optional keep function further limits the clusters.
+func filterService(svc *Service, filters *Filters, keep func(*Cluster) bool) {
+	clusters := []*Cluster{}
+	for _, cluster := range svc.clusters {
+		if filters.matchCluster(cluster) && (keep == nil || keep(cluster)) {
+			clusters = append(clusters, cluster)
+		}
+	}
+	svc.clusters = clusters
+
+	secrets := []secretsmanagertypes.SecretListEntry{}
+	for _, secret := range svc.secrets {
+		if filters.matchSecret(aws.ToString(secret.Name)) {
+			secrets = append(secrets, secret)
+		}
+	}
+	svc.secrets = secrets
+}
+
+// filterChangeSet drops changes to secrets outside the selected subset, such
+// as associated secrets that would otherwise appear stale because they were
+// filtered from the listed secrets.
+func filterChangeSet(cluster *Cluster, filters *Filters) error {
+	if len(filters.secrets)+len(filters.excludeSecrets) == 0 {
+		return nil
+	}
+
+	keep := func(arns []string) []string {
+		kept := []string{}
+		for _, arn := range arns {
+			if filters.matchSecret(secretName(arn)) {
+				kept = append(kept, arn)
+			}
+		}
+		return kept
+	}
+
+	cs := cluster.secretArnChangeSet
+	cs.add = keep(cs.add)
+	cs.remove = keep(cs.remove)
+	cs.unmanaged = keep(cs.unmanaged)
+	if cs.adopt != nil {
+		cs.adopt = keep(cs.adopt)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		match   []string
+		noMatch []string
+		wantErr bool
+	}{
+		{
+			name:    "glob",
+			give:    "prod-*",
+			match:   []string{"prod-payments", "prod-"},
+			noMatch: []string{"dev-payments"},
+		}, {
+			name:    "regex",
+			give:    "/^(prod|stage)-pay/",
+			match:   []string{"prod-payments", "stage-payments"},
+			noMatch: []string{"dev-payments"},
+		}, {
+			name:    "invalid_glob",
+			give:    "[",
+			wantErr: true,
+		}, {
+			name:    "invalid_regex",
+			give:    "/(/",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePattern(tt.give)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			for _, name := range tt.match {
+				assert.True(t, got.Match(name), name)
+			}
+			for _, name := range tt.noMatch {
+				assert.False(t, got.Match(name), name)
+			}
+		})
+	}
+}
+
+func TestFilterService(t *testing.T) {
+	cluster := func(name string, tags map[string]string) *Cluster {
+		return &Cluster{
+			clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String(name), Tags: tags},
+		}
+	}
+	secret := func(name string) secretsmanagertypes.SecretListEntry {
+		return secretsmanagertypes.SecretListEntry{Name: aws.String(name)}
+	}
+
+	tests := []struct {
+		name         string
+		giveFlags    map[string][]string
+		giveKeep     func(*Cluster) bool
+		wantClusters []string
+		wantSecrets  []string
+	}{
+		{
+			name:         "none",
+			wantClusters: []string{"prod-payments", "prod-orders", "dev-payments"},
+			wantSecrets:  []string{"AmazonMSK_payments_alice", "AmazonMSK_orders_bob"},
+		}, {
+			name:         "include_exclude",
+			giveFlags:    map[string][]string{"cluster": {"prod-*"}, "exclude-cluster": {"*-orders"}},
+			wantClusters: []string{"prod-payments"},
+			wantSecrets:  []string{"AmazonMSK_payments_alice", "AmazonMSK_orders_bob"},
+		}, {
+			name:         "cluster_tag",
+			giveFlags:    map[string][]string{"cluster-tag": {"team=payments"}},
+			wantClusters: []string{"prod-payments", "dev-payments"},
+			wantSecrets:  []string{"AmazonMSK_payments_alice", "AmazonMSK_orders_bob"},
+		}, {
+			name:         "secret",
+			giveFlags:    map[string][]string{"secret": {"/_payments_/"}},
+			wantClusters: []string{"prod-payments", "prod-orders", "dev-payments"},
+			wantSecrets:  []string{"AmazonMSK_payments_alice"},
+		}, {
+			name:         "keep",
+			giveFlags:    map[string][]string{"cluster": {"prod-*"}},
+			giveKeep:     func(c *Cluster) bool { return aws.ToString(c.clusterInfo.ClusterName) != "prod-orders" },
+			wantClusters: []string{"prod-payments"},
+			wantSecrets:  []string{"AmazonMSK_payments_alice", "AmazonMSK_orders_bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{}
+			for flag, values := range tt.giveFlags {
+				for _, v := range values {
+					args = append(args, "--"+flag, v)
+				}
+			}
+			t.Setenv("MSK_SECRET_BINDER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+
+			opts, err := parseOptions("apply", args)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			svc := &Service{
+				clusters: []*Cluster{
+					cluster("prod-payments", map[string]string{"team": "payments"}),
+					cluster("prod-orders", map[string]string{"team": "orders"}),
+					cluster("dev-payments", map[string]string{"team": "payments"}),
+				},
+				secrets: []secretsmanagertypes.SecretListEntry{
+					secret("AmazonMSK_payments_alice"),
+					secret("AmazonMSK_orders_bob"),
+				},
+			}
+			filterService(svc, &opts.filters, tt.giveKeep)
+
+			gotClusters := []string{}
+			for _, c := range svc.clusters {
+				gotClusters = append(gotClusters, aws.ToString(c.clusterInfo.ClusterName))
+			}
+			gotSecrets := []string{}
+			for _, s := range svc.secrets {
+				gotSecrets = append(gotSecrets, aws.ToString(s.Name))
+			}
+
+			td.Cmp(t, gotClusters, tt.wantClusters)
+			td.Cmp(t, gotSecrets, tt.wantSecrets)
+		})
+	}
+}
+
+func TestFilterChangeSet(t *testing.T) {
+	pattern, _ := parsePattern("AmazonMSK_payments_*")
+	filters := &Filters{secrets: patternSlice{pattern}}
+
+	cluster := &Cluster{
+		secretArnChangeSet: &SecretChangeSet{
+			add:       []string{"arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_payments_alice-AbCdEf"},
+			remove:    []string{"arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_orders_bob-AbCdEf"},
+			unmanaged: []string{"arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_payments_carol-AbCdEf"},
+		},
+	}
+	filterChangeSet(cluster, filters)
+
+	td.Cmp(t, cluster.secretArnChangeSet, &SecretChangeSet{
+		add:       []string{"arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_payments_alice-AbCdEf"},
+		remove:    []string{},
+		unmanaged: []string{"arn:aws:secretsmanager:us-east-1:111111111111:secret:AmazonMSK_payments_carol-AbCdEf"},
+	})
+}
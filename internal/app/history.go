@@ -0,0 +1,191 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+const (
+	_dateLayout = "2006-01-02"
+)
+
+// HistoryFilter selects journal entries. The name filters match either the
+// name or the arn recorded in the journal.
+type HistoryFilter struct {
+	filters Filters
+	since   time.Time
+	until   time.Time
+}
+
+type HistoryOptions struct {
+	journal string
+	filter  HistoryFilter
+}
+
+func runHistory(args []string) error {
+	opts, err := parseHistoryOptions(args)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readJournal(opts.journal)
+	if err != nil {
+		return err
+	}
+
+	printHistory(filterJournal(entries, opts.filter))
+
+	return nil
+}
+
+func parseHistoryOptions(args []string) (*HistoryOptions, error) {
+	opts := &HistoryOptions{}
+
+	var since, until string
+
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.StringVar(&opts.journal, "journal", defaultJournalPath(), "path to the audit journal")
+	fs.Var(&opts.filter.filters.clusters, "cluster", "cluster name or arn glob or /regex/ to include (repeatable)")
+	fs.Var(&opts.filter.filters.excludeClusters, "exclude-cluster", "cluster name or arn glob or /regex/ to exclude (repeatable)")
+	fs.Var(&opts.filter.filters.secrets, "secret", "secret name or arn glob or /regex/ to include (repeatable)")
+	fs.Var(&opts.filter.filters.excludeSecrets, "exclude-secret", "secret name or arn glob or /regex/ to exclude (repeatable)")
+	fs.StringVar(&since, "since", "", "show entries on or after this date or RFC3339 time")
+	fs.StringVar(&until, "until", "", "show entries on or before this date or RFC3339 time")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("unable to parse flags: %w", err)
+	}
+
+	var err error
+	if since != "" {
+		if opts.filter.since, err = parseDate(since, false); err != nil {
+			return nil, fmt.Errorf("invalid since: %w", err)
+		}
+	}
+	if until != "" {
+		if opts.filter.until, err = parseDate(until, true); err != nil {
+			return nil, fmt.Errorf("invalid until: %w", err)
+		}
+	}
+
+	return opts, nil
+}
+
+// parseDate accepts a date or RFC3339 time. When end is set a date is
+// treated as the end of that day so ranges are inclusive.
+func parseDate(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(_dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected %v or RFC3339: %v", _dateLayout, value)
+	}
+	if end {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return t, nil
+}
+
+func filterJournal(entries []*JournalEntry, filter HistoryFilter) []*JournalEntry {
+	filtered := []*JournalEntry{}
+
+	for _, entry := range entries {
+		if !filter.since.IsZero() && entry.Time.Before(filter.since) {
+			continue
+		}
+		if !filter.until.IsZero() && entry.Time.After(filter.until) {
+			continue
+		}
+		if !matchNameOrArn(entry.ClusterName, entry.ClusterArn, filter.filters.clusters, filter.filters.excludeClusters) {
+			continue
+		}
+		if len(filter.filters.secrets)+len(filter.filters.excludeSecrets) > 0 {
+			secrets := []string{}
+			for _, arn := range entry.SecretArns {
+				if matchNameOrArn(secretName(arn), arn, filter.filters.secrets, filter.filters.excludeSecrets) {
+					secrets = append(secrets, arn)
+				}
+			}
+			if len(secrets) == 0 {
+				continue
+			}
+			e := *entry
+			e.SecretArns = secrets
+			entry = &e
+		}
+		filtered = append(filtered, entry)
+	}
+
+	return filtered
+}
+
+// matchNameOrArn applies matchPatterns to an entry known by both its name and
+// its arn, so either may be given on the command line.
+func matchNameOrArn(name, arn string, include, exclude []*Pattern) bool {
+	match := func(pattern *Pattern) bool {
+		return pattern.Match(name) || pattern.Match(arn)
+	}
+
+	for _, pattern := range exclude {
+		if match(pattern) {
+			return false
+		}
+	}
+
+	if len(include) == 0 {
+		return true
+	}
+
+	for _, pattern := range include {
+		if match(pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func printHistory(entries []*JournalEntry) error {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+
+	tbl := table.New("Time", "Run", "Caller", "Cluster Name", "Action", "Secret Name", "Result")
+	tbl.WithHeaderFormatter(headerFmt)
+
+	for _, entry := range entries {
+		for _, arn := range entry.SecretArns {
+			tbl.AddRow(
+				entry.Time.Format(time.RFC3339),
+				entry.RunID,
+				entry.Caller,
+				entry.ClusterName,
+				entry.Action,
+				secretName(arn),
+				secretResult(entry.Result, arn),
+			)
+		}
+	}
+	tbl.Print()
+
+	return nil
+}
+
+func secretResult(result JournalResult, arn string) string {
+	if result.Status == _resultFailed {
+		return fmt.Sprintf("%v: %v", _resultFailed, result.Error)
+	}
+
+	for _, v := range result.Unprocessed {
+		if v.SecretArn == arn {
+			return fmt.Sprintf("unprocessed: %v", v.ErrorMessage)
+		}
+	}
+
+	return _resultSucceeded
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		giveEnd bool
+		want    time.Time
+		err     bool
+	}{
+		{
+			name: "date",
+			give: "2026-10-19",
+			want: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		}, {
+			name:    "date_end",
+			give:    "2026-10-19",
+			giveEnd: true,
+			want:    time.Date(2026, 10, 19, 23, 59, 59, 999999999, time.UTC),
+		}, {
+			name:    "rfc3339",
+			give:    "2026-10-19T09:30:00Z",
+			giveEnd: true,
+			want:    time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC),
+		}, {
+			name: "invalid",
+			give: "19/10/2026",
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDate(tt.give, tt.giveEnd)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestFilterJournal(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	entries := []*JournalEntry{
+		{
+			Time:        day(17),
+			ClusterName: "payments-prd",
+			ClusterArn:  "arn:aws:kafka:ap-southeast-2:123456789012:cluster/payments-prd/1",
+			SecretArns: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments-123456",
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_admin-234567",
+			},
+		}, {
+			Time:        day(18),
+			ClusterName: "orders-prd",
+			ClusterArn:  "arn:aws:kafka:ap-southeast-2:123456789012:cluster/orders-prd/2",
+			SecretArns: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_orders-345678",
+			},
+		}, {
+			Time:        day(19),
+			ClusterName: "payments-stg",
+			ClusterArn:  "arn:aws:kafka:ap-southeast-2:123456789012:cluster/payments-stg/3",
+			SecretArns: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments-456789",
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		give        []string
+		wantCluster []string
+		wantSecrets [][]string
+	}{
+		{
+			name:        "all",
+			wantCluster: []string{"payments-prd", "orders-prd", "payments-stg"},
+		}, {
+			name:        "cluster_pattern",
+			give:        []string{"-cluster", "payments-*"},
+			wantCluster: []string{"payments-prd", "payments-stg"},
+		}, {
+			name:        "cluster_arn",
+			give:        []string{"-cluster", "arn:aws:kafka:ap-southeast-2:123456789012:cluster/orders-prd/2"},
+			wantCluster: []string{"orders-prd"},
+		}, {
+			name:        "cluster_regex_excluded",
+			give:        []string{"-cluster", "/^payments-/", "-exclude-cluster", "*-stg"},
+			wantCluster: []string{"payments-prd"},
+		}, {
+			name:        "since_until",
+			give:        []string{"-since", "2026-10-18T11:00:00Z", "-until", "2026-10-18"},
+			wantCluster: []string{"orders-prd"},
+		}, {
+			name:        "secret",
+			give:        []string{"-secret", "AmazonMSK_admin"},
+			wantCluster: []string{"payments-prd"},
+			wantSecrets: [][]string{
+				{"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_admin-234567"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseHistoryOptions(tt.give)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got := filterJournal(entries, opts.filter)
+
+			clusters := []string{}
+			secrets := [][]string{}
+			for _, entry := range got {
+				clusters = append(clusters, entry.ClusterName)
+				secrets = append(secrets, entry.SecretArns)
+			}
+			td.Cmp(t, clusters, tt.wantCluster)
+			if tt.wantSecrets != nil {
+				td.Cmp(t, secrets, tt.wantSecrets)
+			}
+		})
+	}
+
+	td.Cmp(t, len(entries[0].SecretArns), 2)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+const (
+	_ignoreTagKey = "msk-secret-binder:ignore"
+)
+
+func isIgnoredCluster(tags map[string]string) bool {
+	value, ok := tags[_ignoreTagKey]
+	if !ok {
+		return false
+	}
+
+	return isTrue(value)
+}
+
+func isIgnoredSecret(tags []secretsmanagertypes.Tag) bool {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == _ignoreTagKey {
+			return isTrue(aws.ToString(tag.Value))
+		}
+	}
+
+	return false
+}
+
+func isTrue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "1":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestIsIgnoredCluster(t *testing.T) {
+	tests := []struct {
+		name string
+		give map[string]string
+		want bool
+	}{
+		{
+			name: "true",
+			give: map[string]string{"msk-secret-binder:ignore": "true"},
+			want: true,
+		}, {
+			name: "yes_mixed_case",
+			give: map[string]string{"msk-secret-binder:ignore": " Yes "},
+			want: true,
+		}, {
+			name: "false",
+			give: map[string]string{"msk-secret-binder:ignore": "false"},
+			want: false,
+		}, {
+			name: "missing",
+			give: map[string]string{"Environment": "prd"},
+			want: false,
+		}, {
+			name: "nil",
+			give: nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isIgnoredCluster(tt.give)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestIsIgnoredSecret(t *testing.T) {
+	tests := []struct {
+		name string
+		give []secretsmanagertypes.Tag
+		want bool
+	}{
+		{
+			name: "true",
+			give: []secretsmanagertypes.Tag{
+				{Key: aws.String("Cluster"), Value: aws.String("example")},
+				{Key: aws.String("msk-secret-binder:ignore"), Value: aws.String("true")},
+			},
+			want: true,
+		}, {
+			name: "false",
+			give: []secretsmanagertypes.Tag{
+				{Key: aws.String("msk-secret-binder:ignore"), Value: aws.String("no")},
+			},
+			want: false,
+		}, {
+			name: "missing",
+			give: []secretsmanagertypes.Tag{
+				{Key: aws.String("Cluster"), Value: aws.String("example")},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isIgnoredSecret(tt.give)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestReconcileClusterSecretsIgnored(t *testing.T) {
+	tests := []struct {
+		name string
+		give *Cluster
+		want *SecretChangeSet
+	}{
+		{
+			name: "cluster_ignored",
+			give: &Cluster{
+				clusterInfo:   &kafkatypes.ClusterInfo{ClusterName: aws.String("example")},
+				ignored:       true,
+				secretArnList: []string{"apple", "pear"},
+			},
+			want: &SecretChangeSet{add: []string{}, remove: []string{}, unmanaged: []string{}},
+		}, {
+			name: "cluster_not_ignored",
+			give: &Cluster{
+				clusterInfo:              &kafkatypes.ClusterInfo{ClusterName: aws.String("example")},
+				secretArnList:            []string{"apple", "pear"},
+				assosciatedSecretArnList: []string{"apple"},
+			},
+			want: &SecretChangeSet{add: []string{"pear"}, remove: []string{}, unmanaged: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconcileClusterSecrets(tt.give)
+			td.Cmp(t, tt.give.secretArnChangeSet, tt.want)
+		})
+	}
+}
+
+func TestMapSecretsToClustersIgnored(t *testing.T) {
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("example")},
+	}
+
+	secrets := []secretsmanagertypes.SecretListEntry{
+		{
+			ARN: aws.String("apple"),
+			Tags: []secretsmanagertypes.Tag{
+				{Key: aws.String("Cluster"), Value: aws.String("example")},
+			},
+		}, {
+			ARN: aws.String("pear"),
+			Tags: []secretsmanagertypes.Tag{
+				{Key: aws.String("Cluster"), Value: aws.String("example")},
+				{Key: aws.String("msk-secret-binder:ignore"), Value: aws.String("true")},
+			},
+		},
+	}
+
+	mapSecretsToClusters(cluster, secrets, []Mapper{tagMapper{}})
+	td.Cmp(t, cluster.secretArnList, []string{"apple"})
+	td.Cmp(t, cluster.ignoredSecretArnList, []string{"pear"})
+}
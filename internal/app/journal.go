@@ -0,0 +1,182 @@
+package app
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+)
+
+const (
+	_actionAssociate    = "associate"
+	_actionDisassociate = "disassociate"
+
+	_resultSucceeded = "succeeded"
+	_resultPartial   = "partial"
+	_resultFailed    = "failed"
+
+	_journalDir  = ".msk-secret-binder"
+	_journalFile = "journal.jsonl"
+)
+
+type Journal struct {
+	path   string
+	runID  string
+	caller string
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+type JournalEntry struct {
+	Time        time.Time     `json:"time"`
+	RunID       string        `json:"run_id"`
+	Caller      string        `json:"caller"`
+	ClusterArn  string        `json:"cluster_arn"`
+	ClusterName string        `json:"cluster_name"`
+	Action      string        `json:"action"`
+	SecretArns  []string      `json:"secret_arns"`
+	Result      JournalResult `json:"result"`
+}
+
+type JournalResult struct {
+	Status      string               `json:"status"`
+	Error       string               `json:"error,omitempty"`
+	Unprocessed []JournalUnprocessed `json:"unprocessed,omitempty"`
+}
+
+type JournalUnprocessed struct {
+	SecretArn    string `json:"secret_arn"`
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+func defaultJournalPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return _journalFile
+	}
+
+	return filepath.Join(home, _journalDir, _journalFile)
+}
+
+func newJournal(path, caller string) (*Journal, error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("unable to generate run id: %w", err)
+	}
+
+	return &Journal{
+		path:   path,
+		runID:  fmt.Sprintf("%v-%v", time.Now().UTC().Format("20060102T150405Z"), hex.EncodeToString(id)),
+		caller: caller,
+		now:    time.Now,
+	}, nil
+}
+
+func newJournalEntry(cluster *Cluster, action string, secretArnList []string, unprocessed []kafkatypes.UnprocessedScramSecret, err error) *JournalEntry {
+	entry := &JournalEntry{
+		ClusterArn:  aws.ToString(cluster.clusterInfo.ClusterArn),
+		ClusterName: aws.ToString(cluster.clusterInfo.ClusterName),
+		Action:      action,
+		SecretArns:  secretArnList,
+		Result: JournalResult{
+			Status: _resultSucceeded,
+		},
+	}
+
+	for _, v := range unprocessed {
+		entry.Result.Unprocessed = append(entry.Result.Unprocessed, JournalUnprocessed{
+			SecretArn:    aws.ToString(v.SecretArn),
+			ErrorCode:    aws.ToString(v.ErrorCode),
+			ErrorMessage: aws.ToString(v.ErrorMessage),
+		})
+	}
+
+	switch {
+	case err != nil:
+		entry.Result.Status = _resultFailed
+		entry.Result.Error = err.Error()
+	case len(unprocessed) > 0:
+		entry.Result.Status = _resultPartial
+	}
+
+	return entry
+}
+
+func (j *Journal) Record(entry *JournalEntry) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry.Time = j.now().UTC()
+	entry.RunID = j.runID
+	entry.Caller = j.caller
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create journal directory: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to open journal: %w", err)
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to encode journal entry: %w", err)
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("unable to write journal entry: %w", err)
+	}
+
+	return nil
+}
+
+func readJournal(path string) (entries []*JournalEntry, err error) {
+	entries = []*JournalEntry{}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return entries, fmt.Errorf("unable to open journal: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry := &JournalEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return entries, fmt.Errorf("unable to decode journal line %v: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("unable to read journal: %w", err)
+	}
+
+	return entries, nil
+}
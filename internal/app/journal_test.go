@@ -0,0 +1,179 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJournalEntry(t *testing.T) {
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("example1"),
+			ClusterArn:  aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/1"),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		giveUnprocessed []kafkatypes.UnprocessedScramSecret
+		giveErr         error
+		want            JournalResult
+	}{
+		{
+			name: "succeeded",
+			want: JournalResult{Status: "succeeded"},
+		}, {
+			name: "partial",
+			giveUnprocessed: []kafkatypes.UnprocessedScramSecret{
+				{
+					SecretArn:    aws.String("pear"),
+					ErrorCode:    aws.String("ValidationException"),
+					ErrorMessage: aws.String("secret is not encrypted with a customer managed key"),
+				},
+			},
+			want: JournalResult{
+				Status: "partial",
+				Unprocessed: []JournalUnprocessed{
+					{
+						SecretArn:    "pear",
+						ErrorCode:    "ValidationException",
+						ErrorMessage: "secret is not encrypted with a customer managed key",
+					},
+				},
+			},
+		}, {
+			name:    "failed",
+			giveErr: errors.New("access denied"),
+			want:    JournalResult{Status: "failed", Error: "access denied"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newJournalEntry(cluster, "associate", []string{"apple", "pear"}, tt.giveUnprocessed, tt.giveErr)
+			td.Cmp(t, got.ClusterArn, "arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/1")
+			td.Cmp(t, got.ClusterName, "example1")
+			td.Cmp(t, got.Action, "associate")
+			td.Cmp(t, got.SecretArns, []string{"apple", "pear"})
+			td.Cmp(t, got.Result, tt.want)
+		})
+	}
+}
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "journal.jsonl")
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+
+	journal := &Journal{
+		path:   path,
+		runID:  "run1",
+		caller: "arn:aws:iam::123456789012:user/operator",
+		now:    func() time.Time { return now },
+	}
+
+	for _, action := range []string{"associate", "disassociate"} {
+		err := journal.Record(&JournalEntry{
+			ClusterName: "example1",
+			Action:      action,
+			SecretArns:  []string{"apple"},
+			Result:      JournalResult{Status: "succeeded"},
+		})
+		assert.NoError(t, err)
+	}
+
+	got, err := readJournal(path)
+	assert.NoError(t, err)
+	td.Cmp(t, got, []*JournalEntry{
+		{
+			Time:        now,
+			RunID:       "run1",
+			Caller:      "arn:aws:iam::123456789012:user/operator",
+			ClusterName: "example1",
+			Action:      "associate",
+			SecretArns:  []string{"apple"},
+			Result:      JournalResult{Status: "succeeded"},
+		}, {
+			Time:        now,
+			RunID:       "run1",
+			Caller:      "arn:aws:iam::123456789012:user/operator",
+			ClusterName: "example1",
+			Action:      "disassociate",
+			SecretArns:  []string{"apple"},
+			Result:      JournalResult{Status: "succeeded"},
+		},
+	})
+}
+
+func TestReadJournalMissing(t *testing.T) {
+	got, err := readJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	assert.NoError(t, err)
+	td.Cmp(t, got, []*JournalEntry{})
+}
+
+func TestReadJournalInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("{}\nnot json\n"), 0o600))
+
+	_, err := readJournal(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to decode journal line 2")
+}
+
+func TestUpdateClusterSecretsBatches(t *testing.T) {
+	add := []string{}
+	for i := 1; i <= 12; i++ {
+		add = append(add, fmt.Sprintf("secret%v", i))
+	}
+
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	kafkaClient := &mockKafkaClientAPI{
+		batches: map[string][][]string{},
+	}
+
+	svc := &Service{
+		kafka: kafkaClient,
+		secretsmanager: &mockSecretsManagerClientAPI{
+			tagged:   map[string][]string{},
+			untagged: map[string][]string{},
+		},
+	}
+
+	fleet := &Fleet{
+		services: []*Service{svc},
+		journal:  &Journal{path: path, runID: "run1", now: time.Now},
+	}
+
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("example1"),
+			ClusterArn:  aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/1"),
+		},
+		service: svc,
+		secretArnChangeSet: &SecretChangeSet{
+			add:    add,
+			remove: []string{"secret13"},
+		},
+	}
+
+	err := updateClusterSecrets(fleet, cluster)
+	assert.NoError(t, err)
+
+	td.Cmp(t, kafkaClient.batches, map[string][][]string{
+		"associate":    {add[:10], add[10:]},
+		"disassociate": {{"secret13"}},
+	})
+
+	entries, err := readJournal(path)
+	assert.NoError(t, err)
+	td.Cmp(t, len(entries), 3)
+}
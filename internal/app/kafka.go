@@ -12,6 +12,10 @@ import (
 	"github.com/aws/smithy-go"
 )
 
+// _batchSize is the maximum number of secrets accepted by a single batch
+// associate or disassociate request.
+const _batchSize = 10
+
 type KafkaClientAPI interface {
 	ListClusters(context.Context, *kafka.ListClustersInput, ...func(*kafka.Options)) (*kafka.ListClustersOutput, error)
 	ListScramSecrets(context.Context, *kafka.ListScramSecretsInput, ...func(*kafka.Options)) (*kafka.ListScramSecretsOutput, error)
@@ -19,10 +23,14 @@ type KafkaClientAPI interface {
 	BatchDisassociateScramSecret(context.Context, *kafka.BatchDisassociateScramSecretInput, ...func(*kafka.Options)) (*kafka.BatchDisassociateScramSecretOutput, error)
 }
 
-func listClusters(cl KafkaClientAPI) (clusterInfo []types.ClusterInfo, err error) {
+func listClusters(cl KafkaClientAPI, pageSize int32) (clusterInfo []types.ClusterInfo, err error) {
 	clusterInfo = []types.ClusterInfo{}
 
-	pagination := kafka.NewListClustersPaginator(cl, &kafka.ListClustersInput{})
+	options := func(o *kafka.ListClustersPaginatorOptions) {
+		o.Limit = pageSize
+	}
+
+	pagination := kafka.NewListClustersPaginator(cl, &kafka.ListClustersInput{}, options)
 	for pagination.HasMorePages() {
 		output, err := pagination.NextPage(context.TODO())
 		if err != nil {
@@ -38,11 +46,11 @@ func listClusters(cl KafkaClientAPI) (clusterInfo []types.ClusterInfo, err error
 	return clusterInfo, nil
 }
 
-func listScramSecrets(cl KafkaClientAPI, clusterArn *string) (secretArnList []string, err error) {
+func listScramSecrets(cl KafkaClientAPI, clusterArn *string, pageSize int32) (secretArnList []string, err error) {
 	secretArnList = []string{}
 
 	options := func(o *kafka.ListScramSecretsPaginatorOptions) {
-		o.Limit = 100
+		o.Limit = pageSize
 	}
 
 	input := &kafka.ListScramSecretsInput{
@@ -65,32 +73,41 @@ func listScramSecrets(cl KafkaClientAPI, clusterArn *string) (secretArnList []st
 	return secretArnList, nil
 }
 
-func associateSecrets(cl KafkaClientAPI, cluster *Cluster) error {
+func associateSecrets(cl KafkaClientAPI, clusterArn *string, secretArnList []string) (unprocessed []types.UnprocessedScramSecret, err error) {
 	out, err := cl.BatchAssociateScramSecret(context.TODO(), &kafka.BatchAssociateScramSecretInput{
-		ClusterArn:    cluster.clusterInfo.ClusterArn,
-		SecretArnList: cluster.secretArnChangeSet.add,
+		ClusterArn:    clusterArn,
+		SecretArnList: secretArnList,
 	})
 	if err != nil {
-		return fmt.Errorf("unable to assosciate secrets: %w", err)
+		return nil, fmt.Errorf("unable to assosciate secrets: %w", err)
 	}
 	for _, v := range out.UnprocessedScramSecrets {
-		log.Printf("unprocess scram secret: %v message: %v", v.SecretArn, v.ErrorMessage)
+		log.Printf("unprocess scram secret: %v message: %v", aws.ToString(v.SecretArn), aws.ToString(v.ErrorMessage))
 	}
 
-	return nil
+	return out.UnprocessedScramSecrets, nil
 }
 
-func disassociateSecrets(cl KafkaClientAPI, cluster *Cluster) error {
+func disassociateSecrets(cl KafkaClientAPI, clusterArn *string, secretArnList []string) (unprocessed []types.UnprocessedScramSecret, err error) {
 	out, err := cl.BatchDisassociateScramSecret(context.TODO(), &kafka.BatchDisassociateScramSecretInput{
-		ClusterArn:    cluster.clusterInfo.ClusterArn,
-		SecretArnList: cluster.secretArnChangeSet.add,
+		ClusterArn:    clusterArn,
+		SecretArnList: secretArnList,
 	})
 	if err != nil {
-		return fmt.Errorf("unable to disassosciate secrets: %w", err)
+		return nil, fmt.Errorf("unable to disassosciate secrets: %w", err)
 	}
 	for _, v := range out.UnprocessedScramSecrets {
-		log.Printf("unprocess scram secret: %v message: %v", v.SecretArn, v.ErrorMessage)
+		log.Printf("unprocess scram secret: %v message: %v", aws.ToString(v.SecretArn), aws.ToString(v.ErrorMessage))
+	}
+
+	return out.UnprocessedScramSecrets, nil
+}
+
+func unprocessedSecretArns(unprocessed []types.UnprocessedScramSecret) []string {
+	arns := []string{}
+	for _, v := range unprocessed {
+		arns = append(arns, aws.ToString(v.SecretArn))
 	}
 
-	return nil
+	return arns
 }
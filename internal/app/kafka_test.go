@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"testing"
 
@@ -16,6 +17,9 @@ import (
 type mockKafkaClientAPI struct {
 	listClustersOutput     []*kafka.ListClustersOutput
 	listScramSecretsOutput []*kafka.ListScramSecretsOutput
+	unprocessed            []types.UnprocessedScramSecret
+	batches                map[string][][]string
+	maxResults             int32
 	err                    error
 }
 
@@ -27,6 +31,10 @@ func (m mockKafkaClientAPI) ListClusters(ctx context.Context, params *kafka.List
 		page, _ = strconv.Atoi(aws.ToString(params.NextToken))
 	}
 
+	if m.maxResults != 0 && params.MaxResults != m.maxResults {
+		return nil, fmt.Errorf("unexpected max results: %v", params.MaxResults)
+	}
+
 	if page < len(m.listClustersOutput)-1 {
 		nextToken = aws.String(strconv.Itoa(page + 1))
 	} else {
@@ -60,11 +68,23 @@ func (m mockKafkaClientAPI) ListScramSecrets(ctx context.Context, params *kafka.
 }
 
 func (m mockKafkaClientAPI) BatchAssociateScramSecret(ctx context.Context, params *kafka.BatchAssociateScramSecretInput, optFns ...func(*kafka.Options)) (*kafka.BatchAssociateScramSecretOutput, error) {
-	return &kafka.BatchAssociateScramSecretOutput{}, nil
+	if m.batches != nil {
+		m.batches["associate"] = append(m.batches["associate"], params.SecretArnList)
+	}
+
+	return &kafka.BatchAssociateScramSecretOutput{
+		UnprocessedScramSecrets: m.unprocessed,
+	}, m.err
 }
 
 func (m mockKafkaClientAPI) BatchDisassociateScramSecret(ctx context.Context, params *kafka.BatchDisassociateScramSecretInput, optFns ...func(*kafka.Options)) (*kafka.BatchDisassociateScramSecretOutput, error) {
-	return &kafka.BatchDisassociateScramSecretOutput{}, nil
+	if m.batches != nil {
+		m.batches["disassociate"] = append(m.batches["disassociate"], params.SecretArnList)
+	}
+
+	return &kafka.BatchDisassociateScramSecretOutput{
+		UnprocessedScramSecrets: m.unprocessed,
+	}, m.err
 }
 
 func TestListClusters(t *testing.T) {
@@ -162,10 +182,11 @@ func TestListClusters(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			cl := &mockKafkaClientAPI{
 				listClustersOutput: tt.give,
+				maxResults:         50,
 				err:                tt.err,
 			}
 
-			got, err := listClusters(cl)
+			got, err := listClusters(cl, 50)
 			assert.ErrorIs(t, err, tt.err)
 			td.Cmp(t, got, tt.want)
 		})
@@ -243,7 +264,7 @@ func TestListScramSecrets(t *testing.T) {
 
 			arn := aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/1")
 
-			got, err := listScramSecrets(cl, arn)
+			got, err := listScramSecrets(cl, arn, 100)
 			assert.ErrorIs(t, err, tt.err)
 			td.Cmp(t, got, tt.want)
 		})
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	_maxClusterChanges = 100
+	_maxTotalChanges   = 500
+	_maxRemovalPercent = 50
+)
+
+type Limits struct {
+	maxClusterChanges int
+	maxTotalChanges   int
+	maxRemovalPercent int
+}
+
+func checkLimits(clusters []*Cluster, limits Limits) []string {
+	violations := []string{}
+
+	total := 0
+	for _, cluster := range clusters {
+		name := clusterLabel(cluster)
+		cs := cluster.secretArnChangeSet
+
+		changes := len(cs.add) + len(cs.remove)
+		total += changes
+
+		if limits.maxClusterChanges > 0 && changes > limits.maxClusterChanges {
+			violations = append(violations, fmt.Sprintf("%v has %v changes (maximum %v)", name, changes, limits.maxClusterChanges))
+		}
+
+		associated := len(cluster.assosciatedSecretArnList)
+		if limits.maxRemovalPercent > 0 && associated > 0 {
+			if len(cs.remove)*100 > limits.maxRemovalPercent*associated {
+				violations = append(violations, fmt.Sprintf("%v removes %v of %v associated secrets (maximum %v%%)", name, len(cs.remove), associated, limits.maxRemovalPercent))
+			}
+		}
+	}
+
+	if limits.maxTotalChanges > 0 && total > limits.maxTotalChanges {
+		violations = append(violations, fmt.Sprintf("plan has %v changes (maximum %v)", total, limits.maxTotalChanges))
+	}
+
+	return violations
+}
+
+func validateLimits(clusters []*Cluster, limits Limits, override bool) error {
+	violations := checkLimits(clusters, limits)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if override {
+		for _, v := range violations {
+			fmt.Printf("warning: change limit overridden: %v\n", v)
+		}
+		fmt.Println()
+		return nil
+	}
+
+	return fmt.Errorf("change limits exceeded: %v", strings.Join(violations, "; "))
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLimits(t *testing.T) {
+	cluster := func(name string, associated, add, remove []string) *Cluster {
+		return &Cluster{
+			clusterInfo:              &kafkatypes.ClusterInfo{ClusterName: aws.String(name)},
+			assosciatedSecretArnList: associated,
+			secretArnChangeSet: &SecretChangeSet{
+				add:    add,
+				remove: remove,
+			},
+		}
+	}
+
+	limits := Limits{
+		maxClusterChanges: 3,
+		maxTotalChanges:   4,
+		maxRemovalPercent: 50,
+	}
+
+	tests := []struct {
+		name string
+		give []*Cluster
+		want []string
+	}{
+		{
+			name: "within_limits",
+			give: []*Cluster{
+				cluster("example1", []string{"apple", "pear"}, []string{"orange"}, []string{"apple"}),
+				cluster("example2", []string{}, []string{"lemon"}, []string{}),
+			},
+			want: []string{},
+		}, {
+			name: "cluster_changes",
+			give: []*Cluster{
+				cluster("example1", []string{}, []string{"apple", "pear", "orange", "lemon"}, []string{}),
+			},
+			want: []string{
+				"example1 has 4 changes (maximum 3)",
+			},
+		}, {
+			name: "total_changes",
+			give: []*Cluster{
+				cluster("example1", []string{}, []string{"apple", "pear", "orange"}, []string{}),
+				cluster("example2", []string{}, []string{"lemon", "peach"}, []string{}),
+			},
+			want: []string{
+				"plan has 5 changes (maximum 4)",
+			},
+		}, {
+			name: "removal_percent",
+			give: []*Cluster{
+				cluster("example1", []string{"apple", "pear", "orange"}, []string{}, []string{"apple", "pear"}),
+			},
+			want: []string{
+				"example1 removes 2 of 3 associated secrets (maximum 50%)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkLimits(tt.give, limits)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestCheckLimitsRemovalBoundary(t *testing.T) {
+	arns := func(n int) []string {
+		list := []string{}
+		for i := 0; i < n; i++ {
+			list = append(list, fmt.Sprintf("secret%v", i))
+		}
+		return list
+	}
+	cluster := func(name string, remove int) *Cluster {
+		return &Cluster{
+			clusterInfo:              &kafkatypes.ClusterInfo{ClusterName: aws.String(name)},
+			assosciatedSecretArnList: arns(200),
+			secretArnChangeSet:       &SecretChangeSet{remove: arns(remove)},
+		}
+	}
+
+	got := checkLimits([]*Cluster{cluster("example1", 100), cluster("example2", 101)}, Limits{maxRemovalPercent: 50})
+	td.Cmp(t, got, []string{
+		"example2 removes 101 of 200 associated secrets (maximum 50%)",
+	})
+}
+
+func TestValidateLimits(t *testing.T) {
+	clusters := []*Cluster{
+		{
+			clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("example1")},
+			secretArnChangeSet: &SecretChangeSet{
+				add: []string{"apple", "pear"},
+			},
+		},
+	}
+
+	limits := Limits{maxClusterChanges: 1}
+
+	assert.EqualError(t, validateLimits(clusters, limits, false), "change limits exceeded: example1 has 2 changes (maximum 1)")
+	assert.NoError(t, validateLimits(clusters, limits, true))
+	assert.NoError(t, validateLimits(clusters, Limits{}, false))
+}
@@ -0,0 +1,376 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+const (
+	_lockBackendFile     = "file"
+	_lockBackendDynamoDB = "dynamodb"
+	_lockBackendNone     = "none"
+
+	_lockFile = "lock.json"
+	_lockID   = "msk-secret-binder"
+	_lockTTL  = time.Hour
+)
+
+var ErrLocked = errors.New("lock is held")
+
+type Locker interface {
+	Lock(info *LockInfo) error
+	Refresh(info *LockInfo) error
+	Unlock(info *LockInfo) error
+	ForceUnlock() error
+}
+
+type LockInfo struct {
+	Owner    string    `json:"owner"`
+	Host     string    `json:"host"`
+	PID      int       `json:"pid"`
+	RunID    string    `json:"run_id"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
+
+type LockOptions struct {
+	backend  string
+	path     string
+	table    string
+	ttl      time.Duration
+	endpoint string
+}
+
+func (l *LockInfo) String() string {
+	return fmt.Sprintf("%v on %v (pid %v, run %v) since %v until %v",
+		l.Owner, l.Host, l.PID, l.RunID, l.Acquired.Format(time.RFC3339), l.Expires.Format(time.RFC3339))
+}
+
+func defaultLockPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return _lockFile
+	}
+
+	return filepath.Join(home, _journalDir, _lockFile)
+}
+
+func runForceUnlock(args []string) error {
+	opts := LockOptions{}
+	awsOpts := AWSOptions{}
+
+	fs := flag.NewFlagSet("force-unlock", flag.ContinueOnError)
+	addLockFlags(fs, &opts)
+	addAWSFlags(fs, &awsOpts)
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("unable to parse flags: %w", err)
+	}
+
+	cfg, err := loadConfig(awsOpts)
+	if err != nil {
+		return err
+	}
+
+	locker, err := newLocker(opts, cfg)
+	if err != nil {
+		return fmt.Errorf("unable to create lock: %w", err)
+	}
+
+	if err := locker.ForceUnlock(); err != nil {
+		return err
+	}
+
+	fmt.Println("Lock released.")
+
+	return nil
+}
+
+func newLocker(opts LockOptions, cfg aws.Config) (Locker, error) {
+	switch opts.backend {
+	case _lockBackendFile:
+		return &FileLocker{path: opts.path, now: time.Now}, nil
+	case _lockBackendDynamoDB:
+		if opts.table == "" {
+			return nil, fmt.Errorf("lock table is required for the %v lock backend", _lockBackendDynamoDB)
+		}
+		return &DynamoDBLocker{
+			client: dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+				if opts.endpoint != "" {
+					o.EndpointResolver = dynamodb.EndpointResolverFromURL(opts.endpoint)
+				}
+			}),
+			table: opts.table,
+			now:   time.Now,
+		}, nil
+	case _lockBackendNone:
+		return &noLocker{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown lock backend: %v", opts.backend)
+}
+
+func newLockInfo(owner, runID string, ttl time.Duration, now time.Time) *LockInfo {
+	host, _ := os.Hostname()
+
+	return &LockInfo{
+		Owner:    owner,
+		Host:     host,
+		PID:      os.Getpid(),
+		RunID:    runID,
+		Acquired: now.UTC(),
+		Expires:  now.Add(ttl).UTC(),
+	}
+}
+
+// LockHeartbeat refreshes a held lock in the background so it does not expire
+// while an apply waits on soaks and prompts between waves.
+type LockHeartbeat struct {
+	locker Locker
+	info   LockInfo
+	ttl    time.Duration
+	now    func() time.Time
+	stop   chan struct{}
+	done   chan struct{}
+	mu     sync.Mutex
+	err    error
+}
+
+func startHeartbeat(locker Locker, info *LockInfo, ttl time.Duration, now func() time.Time) *LockHeartbeat {
+	h := &LockHeartbeat{
+		locker: locker,
+		info:   *info,
+		ttl:    ttl,
+		now:    now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	interval := ttl / 3
+	if interval <= 0 {
+		close(h.done)
+		return h
+	}
+
+	go func() {
+		defer close(h.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.stop:
+				return
+			case <-ticker.C:
+				if err := h.Refresh(); err != nil {
+					fmt.Printf("warning: %v\n", err)
+					return
+				}
+			}
+		}
+	}()
+
+	return h
+}
+
+// Refresh extends the lock to the ttl from now. Once a refresh fails the lock
+// is treated as lost and the error is returned from then on.
+func (h *LockHeartbeat) Refresh() error {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.err != nil {
+		return h.err
+	}
+
+	info := h.info
+	info.Expires = h.now().Add(h.ttl).UTC()
+	if err := h.locker.Refresh(&info); err != nil {
+		h.err = fmt.Errorf("unable to refresh lock: %w", err)
+		return h.err
+	}
+	h.info = info
+
+	return nil
+}
+
+func (h *LockHeartbeat) Stop() {
+	if h == nil {
+		return
+	}
+
+	select {
+	case <-h.stop:
+	default:
+		close(h.stop)
+	}
+	<-h.done
+}
+
+type FileLocker struct {
+	path string
+	now  func() time.Time
+}
+
+func (f *FileLocker) Lock(info *LockInfo) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create lock directory: %w", err)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("unable to encode lock: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(f.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, err = file.Write(data)
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(f.path)
+				return fmt.Errorf("unable to write lock: %w", err)
+			}
+			return nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("unable to create lock: %w", err)
+		}
+
+		holder, err := f.read()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if f.now().Before(holder.Expires) {
+			return fmt.Errorf("%w by %v", ErrLocked, holder)
+		}
+		if err := f.remove(holder); err != nil {
+			return fmt.Errorf("unable to remove expired lock: %w", err)
+		}
+	}
+
+	return fmt.Errorf("unable to acquire lock: %v", f.path)
+}
+
+// Refresh replaces the lock with the new expiry while it is still held by the
+// run. The new lock is written beside the lock and renamed over it.
+func (f *FileLocker) Refresh(info *LockInfo) error {
+	holder, err := f.read()
+	if err != nil {
+		return err
+	}
+	if holder.RunID != info.RunID {
+		return fmt.Errorf("lock is held by another run: %v", holder)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("unable to encode lock: %w", err)
+	}
+
+	tmp := fmt.Sprintf("%v.%v.tmp", f.path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("unable to write lock: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("unable to refresh lock: %w", err)
+	}
+
+	return nil
+}
+
+func (f *FileLocker) Unlock(info *LockInfo) error {
+	holder, err := f.read()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if holder.RunID != info.RunID {
+		return fmt.Errorf("lock is held by another run: %v", holder)
+	}
+
+	return f.remove(holder)
+}
+
+// remove deletes the lock only if it is still the one that was read. The lock
+// is renamed aside first so another run cannot replace it between the check
+// and the removal, and it is restored if it turns out to be a newer lock.
+func (f *FileLocker) remove(holder *LockInfo) error {
+	aside := fmt.Sprintf("%v.%v.old", f.path, os.Getpid())
+	if err := os.Rename(f.path, aside); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("unable to remove lock: %w", err)
+	}
+	defer os.Remove(aside)
+
+	moved, err := readLockFile(aside)
+	if err != nil {
+		return err
+	}
+	if moved.RunID == holder.RunID && moved.Expires.Equal(holder.Expires) {
+		return nil
+	}
+
+	if err := os.Link(aside, f.path); err != nil {
+		return fmt.Errorf("unable to restore lock of run %v: %w", moved.RunID, err)
+	}
+
+	return fmt.Errorf("%w by %v", ErrLocked, moved)
+}
+
+func (f *FileLocker) ForceUnlock() error {
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to remove lock: %w", err)
+	}
+
+	return nil
+}
+
+func (f *FileLocker) read() (*LockInfo, error) {
+	return readLockFile(f.path)
+}
+
+func readLockFile(path string) (*LockInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read lock: %w", err)
+	}
+
+	info := &LockInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("unable to decode lock: %w", err)
+	}
+
+	return info, nil
+}
+
+type noLocker struct{}
+
+func (n *noLocker) Lock(info *LockInfo) error    { return nil }
+func (n *noLocker) Refresh(info *LockInfo) error { return nil }
+func (n *noLocker) Unlock(info *LockInfo) error  { return nil }
+func (n *noLocker) ForceUnlock() error           { return nil }
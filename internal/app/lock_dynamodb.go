@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type DynamoDBClientAPI interface {
+	PutItem(context.Context, *dynamodb.PutItemInput, ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	GetItem(context.Context, *dynamodb.GetItemInput, ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	DeleteItem(context.Context, *dynamodb.DeleteItemInput, ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// DynamoDBLocker stores the lock as a single item keyed by LockID. The
+// Expires attribute holds epoch seconds so it can also be used as the table
+// TTL attribute.
+type DynamoDBLocker struct {
+	client DynamoDBClientAPI
+	table  string
+	now    func() time.Time
+}
+
+func (d *DynamoDBLocker) Lock(info *LockInfo) error {
+	_, err := d.client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName:           aws.String(d.table),
+		Item:                lockItem(info),
+		ConditionExpression: aws.String("attribute_not_exists(LockID) OR Expires < :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(d.now().Unix(), 10)},
+		},
+	})
+
+	var ccfe *types.ConditionalCheckFailedException
+	if errors.As(err, &ccfe) {
+		holder, err := d.read()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrLocked, err)
+		}
+		return fmt.Errorf("%w by %v", ErrLocked, holder)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to acquire lock: %w", err)
+	}
+
+	return nil
+}
+
+// Refresh writes the new expiry only while the item still belongs to the run.
+func (d *DynamoDBLocker) Refresh(info *LockInfo) error {
+	_, err := d.client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName:           aws.String(d.table),
+		Item:                lockItem(info),
+		ConditionExpression: aws.String("RunID = :run"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":run": &types.AttributeValueMemberS{Value: info.RunID},
+		},
+	})
+
+	var ccfe *types.ConditionalCheckFailedException
+	if errors.As(err, &ccfe) {
+		return fmt.Errorf("lock is held by another run")
+	}
+	if err != nil {
+		return fmt.Errorf("unable to refresh lock: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DynamoDBLocker) Unlock(info *LockInfo) error {
+	_, err := d.client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName:           aws.String(d.table),
+		Key:                 lockKey(),
+		ConditionExpression: aws.String("attribute_not_exists(LockID) OR RunID = :run"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":run": &types.AttributeValueMemberS{Value: info.RunID},
+		},
+	})
+
+	var ccfe *types.ConditionalCheckFailedException
+	if errors.As(err, &ccfe) {
+		return fmt.Errorf("lock is held by another run")
+	}
+	if err != nil {
+		return fmt.Errorf("unable to release lock: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DynamoDBLocker) ForceUnlock() error {
+	_, err := d.client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(d.table),
+		Key:       lockKey(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to remove lock: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DynamoDBLocker) read() (*LockInfo, error) {
+	out, err := d.client.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName:      aws.String(d.table),
+		Key:            lockKey(),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read lock: %w", err)
+	}
+	if out.Item == nil {
+		return nil, fmt.Errorf("lock released while reading")
+	}
+
+	return lockInfoFromItem(out.Item), nil
+}
+
+func lockKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"LockID": &types.AttributeValueMemberS{Value: _lockID},
+	}
+}
+
+func lockItem(info *LockInfo) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"LockID":   &types.AttributeValueMemberS{Value: _lockID},
+		"Owner":    &types.AttributeValueMemberS{Value: info.Owner},
+		"Host":     &types.AttributeValueMemberS{Value: info.Host},
+		"PID":      &types.AttributeValueMemberN{Value: strconv.Itoa(info.PID)},
+		"RunID":    &types.AttributeValueMemberS{Value: info.RunID},
+		"Acquired": &types.AttributeValueMemberS{Value: info.Acquired.Format(time.RFC3339)},
+		"Expires":  &types.AttributeValueMemberN{Value: strconv.FormatInt(info.Expires.Unix(), 10)},
+	}
+}
+
+func lockInfoFromItem(item map[string]types.AttributeValue) *LockInfo {
+	info := &LockInfo{}
+
+	str := func(key string) string {
+		if v, ok := item[key].(*types.AttributeValueMemberS); ok {
+			return v.Value
+		}
+		return ""
+	}
+	num := func(key string) int64 {
+		if v, ok := item[key].(*types.AttributeValueMemberN); ok {
+			n, _ := strconv.ParseInt(v.Value, 10, 64)
+			return n
+		}
+		return 0
+	}
+
+	info.Owner = str("Owner")
+	info.Host = str("Host")
+	info.PID = int(num("PID"))
+	info.RunID = str("RunID")
+	info.Acquired, _ = time.Parse(time.RFC3339, str("Acquired"))
+	info.Expires = time.Unix(num("Expires"), 0).UTC()
+
+	return info
+}
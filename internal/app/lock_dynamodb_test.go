@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDynamoDBClientAPI is an in-memory stand-in for a lock table that
+// evaluates the condition expressions used by DynamoDBLocker.
+type mockDynamoDBClientAPI struct {
+	items map[string]map[string]types.AttributeValue
+}
+
+func (m *mockDynamoDBClientAPI) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	id := params.Item["LockID"].(*types.AttributeValueMemberS).Value
+
+	existing, ok := m.items[id]
+	if run, isRefresh := params.ExpressionAttributeValues[":run"]; isRefresh {
+		if !ok || existing["RunID"].(*types.AttributeValueMemberS).Value != run.(*types.AttributeValueMemberS).Value {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("conditional request failed")}
+		}
+	} else if ok && params.ConditionExpression != nil {
+		now, _ := strconv.ParseInt(params.ExpressionAttributeValues[":now"].(*types.AttributeValueMemberN).Value, 10, 64)
+		expires, _ := strconv.ParseInt(existing["Expires"].(*types.AttributeValueMemberN).Value, 10, 64)
+		if expires >= now {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("conditional request failed")}
+		}
+	}
+	m.items[id] = params.Item
+
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m *mockDynamoDBClientAPI) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	id := params.Key["LockID"].(*types.AttributeValueMemberS).Value
+
+	return &dynamodb.GetItemOutput{Item: m.items[id]}, nil
+}
+
+func (m *mockDynamoDBClientAPI) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	id := params.Key["LockID"].(*types.AttributeValueMemberS).Value
+
+	if existing, ok := m.items[id]; ok && params.ConditionExpression != nil {
+		run := params.ExpressionAttributeValues[":run"].(*types.AttributeValueMemberS).Value
+		if existing["RunID"].(*types.AttributeValueMemberS).Value != run {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("conditional request failed")}
+		}
+	}
+	delete(m.items, id)
+
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func TestDynamoDBLocker(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	locker := &DynamoDBLocker{
+		client: &mockDynamoDBClientAPI{items: map[string]map[string]types.AttributeValue{}},
+		table:  "locks",
+		now:    func() time.Time { return now },
+	}
+
+	first := &LockInfo{Owner: "alice", Host: "laptop", PID: 42, RunID: "run1", Acquired: now, Expires: now.Add(time.Hour)}
+	second := &LockInfo{Owner: "bob", Host: "cron", PID: 7, RunID: "run2", Acquired: now, Expires: now.Add(time.Hour)}
+
+	assert.NoError(t, locker.Lock(first))
+
+	err := locker.Lock(second)
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.Contains(t, err.Error(), "alice on laptop (pid 42, run run1)")
+
+	assert.EqualError(t, locker.Refresh(second), "lock is held by another run")
+	refreshed := *first
+	refreshed.Expires = now.Add(2 * time.Hour)
+	assert.NoError(t, locker.Refresh(&refreshed))
+
+	holder, err := locker.read()
+	assert.NoError(t, err)
+	td.Cmp(t, holder.Expires, refreshed.Expires)
+
+	assert.EqualError(t, locker.Unlock(second), "lock is held by another run")
+	assert.NoError(t, locker.Unlock(first))
+	assert.EqualError(t, locker.Refresh(first), "lock is held by another run")
+
+	assert.NoError(t, locker.Lock(second))
+	assert.NoError(t, locker.ForceUnlock())
+	assert.NoError(t, locker.Lock(first))
+
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, locker.Lock(second))
+
+	holder, err = locker.read()
+	assert.NoError(t, err)
+	td.Cmp(t, holder, second)
+}
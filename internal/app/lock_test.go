@@ -0,0 +1,175 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLocker(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	locker := &FileLocker{
+		path: filepath.Join(t.TempDir(), "locks", "lock.json"),
+		now:  func() time.Time { return now },
+	}
+
+	first := &LockInfo{Owner: "alice", RunID: "run1", Acquired: now, Expires: now.Add(time.Hour)}
+	second := &LockInfo{Owner: "bob", RunID: "run2", Acquired: now, Expires: now.Add(time.Hour)}
+
+	assert.NoError(t, locker.Lock(first))
+
+	err := locker.Lock(second)
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.Contains(t, err.Error(), "alice")
+
+	assert.Error(t, locker.Refresh(second))
+	refreshed := *first
+	refreshed.Expires = now.Add(2 * time.Hour)
+	assert.NoError(t, locker.Refresh(&refreshed))
+
+	holder, err := locker.read()
+	assert.NoError(t, err)
+	td.Cmp(t, holder.Expires, refreshed.Expires)
+
+	assert.Error(t, locker.Unlock(second))
+	assert.NoError(t, locker.Unlock(first))
+	assert.NoError(t, locker.Unlock(first))
+
+	assert.NoError(t, locker.Lock(second))
+	assert.NoError(t, locker.ForceUnlock())
+	assert.NoError(t, locker.Lock(first))
+
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, locker.Lock(second))
+
+	holder, err = locker.read()
+	assert.NoError(t, err)
+	td.Cmp(t, holder.RunID, "run2")
+}
+
+func TestFileLockerRemove(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+
+	locker := &FileLocker{
+		path: filepath.Join(dir, "lock.json"),
+		now:  func() time.Time { return now },
+	}
+
+	expired := &LockInfo{Owner: "alice", RunID: "run1", Acquired: now.Add(-2 * time.Hour), Expires: now.Add(-time.Hour)}
+	fresh := &LockInfo{Owner: "bob", RunID: "run2", Acquired: now, Expires: now.Add(time.Hour)}
+
+	// Another run replaced the expired lock after it was read.
+	assert.NoError(t, locker.Lock(fresh))
+	err := locker.remove(expired)
+	assert.ErrorIs(t, err, ErrLocked)
+	assert.Contains(t, err.Error(), "bob")
+
+	holder, err := locker.read()
+	assert.NoError(t, err)
+	td.Cmp(t, holder.RunID, "run2")
+
+	assert.NoError(t, locker.remove(holder))
+	_, err = locker.read()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	td.Cmp(t, len(entries), 0)
+}
+
+type mockLocker struct {
+	noLocker
+	mu        sync.Mutex
+	refreshed []time.Time
+	err       error
+}
+
+func (m *mockLocker) Refresh(info *LockInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	m.refreshed = append(m.refreshed, info.Expires)
+
+	return nil
+}
+
+func TestLockHeartbeat(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	info := &LockInfo{Owner: "alice", RunID: "run1", Acquired: now, Expires: now.Add(30 * time.Millisecond)}
+
+	locker := &mockLocker{}
+	heartbeat := startHeartbeat(locker, info, 30*time.Millisecond, func() time.Time { return now })
+	time.Sleep(50 * time.Millisecond)
+	heartbeat.Stop()
+	heartbeat.Stop()
+
+	locker.mu.Lock()
+	assert.NotEmpty(t, locker.refreshed)
+	td.Cmp(t, locker.refreshed[0], now.Add(30*time.Millisecond))
+	locker.mu.Unlock()
+
+	lost := &mockLocker{err: errors.New("lock is held by another run")}
+	heartbeat = startHeartbeat(lost, info, time.Hour, time.Now)
+	assert.EqualError(t, heartbeat.Refresh(), "unable to refresh lock: lock is held by another run")
+	lost.err = nil
+	assert.Error(t, heartbeat.Refresh())
+	heartbeat.Stop()
+
+	var none *LockHeartbeat
+	assert.NoError(t, none.Refresh())
+	none.Stop()
+}
+
+func TestNewLocker(t *testing.T) {
+	tests := []struct {
+		name string
+		give LockOptions
+		err  string
+	}{
+		{
+			name: "file",
+			give: LockOptions{backend: "file", path: "lock.json"},
+		}, {
+			name: "dynamodb",
+			give: LockOptions{backend: "dynamodb", table: "locks"},
+		}, {
+			name: "dynamodb_without_table",
+			give: LockOptions{backend: "dynamodb"},
+			err:  "lock table is required for the dynamodb lock backend",
+		}, {
+			name: "none",
+			give: LockOptions{backend: "none"},
+		}, {
+			name: "unknown",
+			give: LockOptions{backend: "redis"},
+			err:  "unknown lock backend: redis",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newLocker(tt.give, testConfig())
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func testConfig() aws.Config {
+	return aws.Config{Region: "ap-southeast-2"}
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// Mapper decides whether a secret binds to a cluster and names the rule that
+// produced the binding.
+type Mapper interface {
+	Map(cluster *Cluster, secret secretsmanagertypes.SecretListEntry) (rule string, ok bool)
+}
+
+type tagMapper struct{}
+
+func (tagMapper) Map(cluster *Cluster, secret secretsmanagertypes.SecretListEntry) (string, bool) {
+	return clusterSecretRule(cluster, secret.Tags)
+}
+
+// namingMapper binds secrets named after a cluster, such as
+// AmazonMSK_<cluster>_<user>, using a pattern with cluster and user groups.
+type namingMapper struct {
+	re *regexp.Regexp
+}
+
+func newNamingMapper(pattern string) (*namingMapper, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid naming pattern %q: %w", pattern, err)
+	}
+
+	for _, group := range []string{"cluster", "user"} {
+		if re.SubexpIndex(group) == -1 {
+			return nil, fmt.Errorf("invalid naming pattern %q: missing named group %q", pattern, group)
+		}
+	}
+
+	return &namingMapper{re: re}, nil
+}
+
+func (m *namingMapper) Map(cluster *Cluster, secret secretsmanagertypes.SecretListEntry) (string, bool) {
+	match := m.re.FindStringSubmatch(aws.ToString(secret.Name))
+	if match == nil {
+		return "", false
+	}
+
+	if match[m.re.SubexpIndex("cluster")] != aws.ToString(cluster.clusterInfo.ClusterName) {
+		return "", false
+	}
+
+	return fmt.Sprintf("naming /%v/ user %v", m.re, match[m.re.SubexpIndex("user")]), true
+}
+
+// newMappers returns the mappers for the service, running the plugin when one
+// is configured.
+func newMappers(svc *Service, opts *Options) ([]Mapper, error) {
+	mappers, err := newConfigMappers(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return addPluginMapper(svc, pluginConfig(opts), mappers)
+}
+
+// newConfigMappers returns the mappers set up by the options alone.
+func newConfigMappers(opts *Options) ([]Mapper, error) {
+	mappers := []Mapper{tagMapper{}}
+
+	if opts.config != nil && opts.config.NamingPattern != "" {
+		m, err := newNamingMapper(opts.config.NamingPattern)
+		if err != nil {
+			return nil, err
+		}
+		mappers = append(mappers, m)
+	}
+
+	if opts.config != nil && opts.config.UserListTagKey != "" {
+		mappers = append(mappers, declaredMapper{tagKey: opts.config.UserListTagKey})
+	}
+
+	if len(opts.rules) > 0 {
+		mappers = append(mappers, ruleMapper{rules: opts.rules})
+	}
+
+	return mappers, nil
+}
+
+func pluginConfig(opts *Options) *PluginConfig {
+	if opts.config == nil {
+		return nil
+	}
+
+	return opts.config.Plugin
+}
+
+// mapSecret returns the rule of the first mapper that binds the secret.
+func mapSecret(cluster *Cluster, secret secretsmanagertypes.SecretListEntry, mappers []Mapper) (string, bool) {
+	for _, m := range mappers {
+		if rule, ok := m.Map(cluster, secret); ok {
+			return rule, true
+		}
+	}
+
+	return "", false
+}
+
+// Binding is a secret already associated with a cluster and the rule that
+// still binds it.
+type Binding struct {
+	cluster *Cluster
+	name    string
+	rule    string
+}
+
+// findExistingBindings returns the rules for associated secrets, which are not
+// in the change set as they need no change.
+func findExistingBindings(clusters []*Cluster) []*Binding {
+	bindings := []*Binding{}
+	for _, cluster := range clusters {
+		existing := []*Binding{}
+		for _, arn := range cluster.assosciatedSecretArnList {
+			rule, ok := cluster.bindings[arn]
+			if !ok {
+				continue
+			}
+			existing = append(existing, &Binding{cluster: cluster, name: secretName(arn), rule: rule})
+		}
+		sort.Slice(existing, func(i, j int) bool {
+			return existing[i].name < existing[j].name
+		})
+		bindings = append(bindings, existing...)
+	}
+
+	return bindings
+}
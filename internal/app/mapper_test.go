@@ -0,0 +1,111 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+const _testNamingPattern = `^AmazonMSK_(?P<cluster>[^_]+)_(?P<user>.+)$`
+
+func TestNewNamingMapper(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		wantErr bool
+	}{
+		{name: "valid", give: _testNamingPattern},
+		{name: "invalid", give: `^AmazonMSK_(`, wantErr: true},
+		{name: "missing_cluster", give: `^AmazonMSK_(?P<user>.+)$`, wantErr: true},
+		{name: "missing_user", give: `^AmazonMSK_(?P<cluster>.+)$`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newNamingMapper(tt.give)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestMapSecretsToClustersRules(t *testing.T) {
+	naming, err := newNamingMapper(_testNamingPattern)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("payments")},
+	}
+
+	secrets := []secretsmanagertypes.SecretListEntry{
+		{
+			Name: aws.String("AmazonMSK_payments_alice"),
+			ARN:  aws.String("alice"),
+		}, {
+			Name: aws.String("AmazonMSK_orders_bob"),
+			ARN:  aws.String("bob"),
+		}, {
+			Name: aws.String("AmazonMSK_carol"),
+			ARN:  aws.String("carol"),
+			Tags: []secretsmanagertypes.Tag{
+				{Key: aws.String("Cluster"), Value: aws.String("payments")},
+			},
+		}, {
+			Name: aws.String("AmazonMSK_payments_dave"),
+			ARN:  aws.String("dave"),
+			Tags: []secretsmanagertypes.Tag{
+				{Key: aws.String("Cluster"), Value: aws.String("payments")},
+			},
+		},
+	}
+
+	mapSecretsToClusters(cluster, secrets, []Mapper{tagMapper{}, naming})
+
+	td.Cmp(t, cluster.secretArnList, []string{"alice", "carol", "dave"})
+	td.Cmp(t, cluster.bindings, map[string]string{
+		"alice": "naming /" + _testNamingPattern + "/ user alice",
+		"carol": "tag Cluster",
+		"dave":  "tag Cluster",
+	})
+}
+
+func TestSecretChangeSetFormat(t *testing.T) {
+	cs := SecretChangeSet{
+		add:    []string{"alice", "bob"},
+		remove: []string{"carol"},
+	}
+
+	got := cs.format(map[string]string{"alice": "naming user alice"})
+	assert.Equal(t, "+alice (naming user alice)\n+bob\n-carol\n", got)
+}
+
+func TestFindExistingBindings(t *testing.T) {
+	payments := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("payments")},
+		assosciatedSecretArnList: []string{
+			"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments_bob-234567",
+			"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments_alice-123456",
+			"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_manual-345678",
+		},
+		bindings: map[string]string{
+			"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments_alice-123456": "tag Cluster",
+			"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments_bob-234567":   "naming /" + _testNamingPattern + "/ user bob",
+			"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments_carol-456789": "tag Cluster",
+		},
+	}
+
+	td.Cmp(t, findExistingBindings([]*Cluster{payments}), []*Binding{
+		{cluster: payments, name: "AmazonMSK_payments_alice", rule: "tag Cluster"},
+		{cluster: payments, name: "AmazonMSK_payments_bob", rule: "naming /" + _testNamingPattern + "/ user bob"},
+	})
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Options struct {
+	quotaWarn        int
+	quotaLimit       int
+	removals         bool
+	removalsFlag     bool
+	adopt            bool
+	protected        stringSlice
+	strict           bool
+	limits           Limits
+	override         bool
+	waves            waveSlice
+	soak             time.Duration
+	windows          clusterWindowSlice
+	freezes          freezeSlice
+	onDrift          string
+	journal          string
+	run              string
+	checkpoint       string
+	resume           bool
+	lock             LockOptions
+	regions          string
+	accounts         string
+	accountRole      string
+	writeAccountRole string
+	aws              AWSOptions
+	config           *Config
+	filters          Filters
+	rules            []*Rule
+}
+
+type AWSOptions struct {
+	profile      string
+	region       string
+	roleArn      string
+	writeRoleArn string
+	externalID   string
+	sessionName  string
+	endpoints    Endpoints
+}
+
+type Endpoints struct {
+	kafka          string
+	secretsmanager string
+	sts            string
+}
+
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func parseOptions(command string, args []string) (*Options, error) {
+	opts := &Options{}
+
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	configPath := fs.String("config", defaultConfigPath(), "path to the config file")
+	fs.IntVar(&opts.quotaWarn, "quota-warn", _scramSecretQuotaWarn, "warn when a cluster would have this many scram secrets")
+	fs.IntVar(&opts.quotaLimit, "quota-limit", _scramSecretQuotaLimit, "maximum number of scram secrets a cluster can have")
+
+	fs.BoolVar(&opts.removals, "remove", false, "disassociate managed secrets that no longer map to a cluster")
+	fs.BoolVar(&opts.adopt, "adopt", false, "tag associated secrets that map to a cluster as managed")
+	fs.Var(&opts.protected, "protect", "secret name pattern or arn that must never be disassociated (repeatable)")
+	fs.BoolVar(&opts.strict, "strict", false, "fail when the plan removes a protected secret")
+	fs.IntVar(&opts.limits.maxClusterChanges, "max-cluster-changes", _maxClusterChanges, "maximum changes to a single cluster (0 for unlimited)")
+	fs.IntVar(&opts.limits.maxTotalChanges, "max-total-changes", _maxTotalChanges, "maximum changes across all clusters (0 for unlimited)")
+	fs.IntVar(&opts.limits.maxRemovalPercent, "max-removal-percent", _maxRemovalPercent, "maximum percentage of a cluster's associated secrets removed (0 for unlimited)")
+	fs.BoolVar(&opts.override, "override-limits", false, "apply changes that exceed the change limits")
+	fs.Var(&opts.waves, "wave", "rollout wave as name=selector[,selector] using name globs or tag:key=value (repeatable)")
+	fs.DurationVar(&opts.soak, "soak", 0, "time to wait between waves instead of prompting")
+	fs.Var(&opts.windows, "window", "maintenance window as pattern=days start-end [location], days as Mon-Fri, Sat+Sun or daily, for matching clusters (repeatable)")
+	fs.Var(&opts.freezes, "freeze", "change freeze as start/end in RFC3339, space separated (repeatable)")
+	fs.StringVar(&opts.onDrift, "on-drift", _onDriftReplan, "action when scram secrets change before applying (replan or abort)")
+	fs.StringVar(&opts.journal, "journal", defaultJournalPath(), "path to the audit journal")
+
+	fs.StringVar(&opts.checkpoint, "checkpoint", defaultCheckpointPath(), "path to the apply checkpoint")
+	fs.Var(&opts.filters.clusters, "cluster", "cluster name glob or /regex/ to include (repeatable)")
+	fs.Var(&opts.filters.excludeClusters, "exclude-cluster", "cluster name glob or /regex/ to exclude (repeatable)")
+	fs.Var(&opts.filters.secrets, "secret", "secret name glob or /regex/ to include (repeatable)")
+	fs.Var(&opts.filters.excludeSecrets, "exclude-secret", "secret name glob or /regex/ to exclude (repeatable)")
+	fs.Var(&opts.filters.clusterTags, "cluster-tag", "cluster tag as key=value that selected clusters must have (repeatable)")
+	addLockFlags(fs, &opts.lock)
+	addAWSFlags(fs, &opts.aws)
+	fs.StringVar(&opts.regions, "regions", "", "comma separated regions to reconcile or all for every enabled region")
+	fs.StringVar(&opts.accounts, "accounts", "", "comma separated account ids to reconcile or organization for every active account")
+	fs.StringVar(&opts.accountRole, "account-role", _accountRole, "role name to assume in each account")
+	fs.StringVar(&opts.writeAccountRole, "write-account-role", "", "role name to assume in each account when changing associations")
+
+	if command == "apply" {
+		fs.BoolVar(&opts.resume, "resume", false, "continue an interrupted apply from the checkpoint")
+	}
+
+	if command == "rollback" {
+		fs.StringVar(&opts.run, "run", "", "journal run id to roll back (defaults to the latest run)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("unable to parse flags: %w", err)
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	conf, err := loadConfigFile(*configPath, set["config"])
+	if err != nil {
+		return nil, err
+	}
+	opts.config = conf
+
+	opts.rules = conf.rules
+
+	opts.removalsFlag = set["remove"]
+	if !opts.removalsFlag {
+		opts.removals = conf.Removals
+	}
+	opts.protected = append(opts.protected, conf.Protected...)
+
+	if err := validateProtectedPatterns(opts.protected); err != nil {
+		return nil, err
+	}
+
+	if opts.quotaWarn > opts.quotaLimit {
+		return nil, fmt.Errorf("quota warn threshold %v exceeds quota limit %v", opts.quotaWarn, opts.quotaLimit)
+	}
+
+	if opts.onDrift != _onDriftReplan && opts.onDrift != _onDriftAbort {
+		return nil, fmt.Errorf("invalid on-drift action %q: expected %v or %v", opts.onDrift, _onDriftReplan, _onDriftAbort)
+	}
+
+	if opts.accounts != "" && opts.aws.writeRoleArn != "" {
+		return nil, fmt.Errorf("write role arn cannot be used with accounts: use write account role instead")
+	}
+
+	return opts, nil
+}
+
+func parseList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		list = append(list, item)
+	}
+
+	return list
+}
+
+func addAWSFlags(fs *flag.FlagSet, opts *AWSOptions) {
+	fs.StringVar(&opts.profile, "profile", "", "shared config profile to use")
+	fs.StringVar(&opts.region, "region", "", "region to use instead of the default")
+	fs.StringVar(&opts.roleArn, "role-arn", "", "role to assume before making any requests")
+	fs.StringVar(&opts.writeRoleArn, "write-role-arn", "", "role to assume when changing associations (must allow sts:TagSession)")
+	fs.StringVar(&opts.externalID, "external-id", "", "external id to use when assuming roles")
+	fs.StringVar(&opts.sessionName, "session-name", _sessionName, "session name to use when assuming roles")
+	fs.StringVar(&opts.endpoints.kafka, "kafka-endpoint", "", "endpoint url for kafka requests")
+	fs.StringVar(&opts.endpoints.secretsmanager, "secretsmanager-endpoint", "", "endpoint url for secretsmanager requests")
+	fs.StringVar(&opts.endpoints.sts, "sts-endpoint", "", "endpoint url for sts requests")
+}
+
+func addLockFlags(fs *flag.FlagSet, opts *LockOptions) {
+	fs.StringVar(&opts.backend, "lock", _lockBackendFile, "lock backend (file, dynamodb or none)")
+	fs.StringVar(&opts.path, "lock-file", defaultLockPath(), "path to the lock file for the file backend")
+	fs.StringVar(&opts.table, "lock-table", "", "dynamodb table for the dynamodb backend")
+	fs.DurationVar(&opts.ttl, "lock-ttl", _lockTTL, "time after which a held lock expires")
+	fs.StringVar(&opts.endpoint, "lock-endpoint", "", "endpoint url for dynamodb requests for the dynamodb backend")
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+const (
+	_ownershipTagKeyPrefix = "msk-secret-binder:cluster:"
+	_ownershipTagValue     = "managed"
+)
+
+// ownershipTagKey keys ownership on the cluster uuid at the end of the arn so
+// a new cluster created with the same name does not inherit it.
+func ownershipTagKey(clusterArn *string) string {
+	arn := aws.ToString(clusterArn)
+	return _ownershipTagKeyPrefix + arn[strings.LastIndex(arn, "/")+1:]
+}
+
+func isOwnedSecret(clusterInfo *kafkatypes.ClusterInfo, tags []secretsmanagertypes.Tag) bool {
+	key := ownershipTagKey(clusterInfo.ClusterArn)
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == key {
+			return true
+		}
+	}
+
+	return false
+}
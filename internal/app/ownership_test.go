@@ -0,0 +1,204 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileClusterSecretsOwnership(t *testing.T) {
+	tests := []struct {
+		name         string
+		giveRemovals bool
+		giveAdopt    bool
+		giveOwned    []string
+		want         *SecretChangeSet
+	}{
+		{
+			name:         "removals_disabled",
+			giveRemovals: false,
+			giveOwned:    []string{"orange", "lemon"},
+			want: &SecretChangeSet{
+				add:       []string{"pear"},
+				remove:    []string{},
+				unmanaged: []string{},
+			},
+		}, {
+			name:         "all_owned",
+			giveRemovals: true,
+			giveOwned:    []string{"orange", "lemon"},
+			want: &SecretChangeSet{
+				add:       []string{"pear"},
+				remove:    []string{"orange", "lemon"},
+				unmanaged: []string{},
+			},
+		}, {
+			name:         "some_owned",
+			giveRemovals: true,
+			giveOwned:    []string{"lemon"},
+			want: &SecretChangeSet{
+				add:       []string{"pear"},
+				remove:    []string{"lemon"},
+				unmanaged: []string{"orange"},
+			},
+		}, {
+			name:         "none_owned",
+			giveRemovals: true,
+			giveOwned:    []string{},
+			want: &SecretChangeSet{
+				add:       []string{"pear"},
+				remove:    []string{},
+				unmanaged: []string{"orange", "lemon"},
+			},
+		}, {
+			name:         "adopt",
+			giveRemovals: true,
+			giveAdopt:    true,
+			giveOwned:    []string{"lemon"},
+			want: &SecretChangeSet{
+				add:       []string{"pear"},
+				remove:    []string{"lemon"},
+				unmanaged: []string{"orange"},
+				adopt:     []string{"apple"},
+			},
+		}, {
+			name:      "adopt_owned",
+			giveAdopt: true,
+			giveOwned: []string{"apple"},
+			want: &SecretChangeSet{
+				add:       []string{"pear"},
+				remove:    []string{},
+				unmanaged: []string{},
+				adopt:     []string{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &Cluster{
+				clusterInfo:              &kafkatypes.ClusterInfo{ClusterName: aws.String("example")},
+				secretArnList:            []string{"apple", "pear"},
+				assosciatedSecretArnList: []string{"apple", "orange", "lemon", "peach"},
+				ignoredSecretArnList:     []string{"peach"},
+				ownedSecretArnList:       tt.giveOwned,
+				removals:                 tt.giveRemovals,
+				adopt:                    tt.giveAdopt,
+			}
+
+			reconcileClusterSecrets(cluster)
+			td.Cmp(t, cluster.secretArnChangeSet, tt.want)
+		})
+	}
+}
+
+func TestIsOwnedSecret(t *testing.T) {
+	clusterInfo := &kafkatypes.ClusterInfo{
+		ClusterName: aws.String("example1"),
+		ClusterArn:  aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/a1b2c3d4-1"),
+	}
+
+	tests := []struct {
+		name string
+		give string
+		want bool
+	}{
+		{
+			name: "uuid",
+			give: "msk-secret-binder:cluster:a1b2c3d4-1",
+			want: true,
+		}, {
+			name: "replaced_cluster",
+			give: "msk-secret-binder:cluster:e5f6a7b8-2",
+			want: false,
+		}, {
+			name: "other",
+			give: "Cluster",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := []secretsmanagertypes.Tag{{Key: aws.String(tt.give), Value: aws.String(_ownershipTagValue)}}
+			td.Cmp(t, isOwnedSecret(clusterInfo, tags), tt.want)
+		})
+	}
+}
+
+func TestUpdateClusterSecretsOwnership(t *testing.T) {
+	clusterArn := "arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/a1b2c3d4-1"
+	tagKey := "msk-secret-binder:cluster:a1b2c3d4-1"
+
+	tests := []struct {
+		name            string
+		giveUnprocessed []kafkatypes.UnprocessedScramSecret
+		giveTagErr      error
+		wantBatches     map[string][][]string
+		wantTagged      map[string][]string
+		wantUntagged    map[string][]string
+		wantErr         bool
+	}{
+		{
+			name:         "associated",
+			wantBatches:  map[string][][]string{"associate": {{"apple", "pear"}}},
+			wantTagged:   map[string][]string{"apple": {tagKey}, "pear": {tagKey}},
+			wantUntagged: map[string][]string{},
+		}, {
+			name:            "unprocessed",
+			giveUnprocessed: []kafkatypes.UnprocessedScramSecret{{SecretArn: aws.String("pear")}},
+			wantBatches:     map[string][][]string{"associate": {{"apple", "pear"}}},
+			wantTagged:      map[string][]string{"apple": {tagKey}, "pear": {tagKey}},
+			wantUntagged:    map[string][]string{"pear": {tagKey}},
+		}, {
+			// A tagging failure must stop the association so the secrets stay
+			// in the next plan.
+			name:         "tag_failure",
+			giveTagErr:   errors.New("access denied"),
+			wantBatches:  map[string][][]string{},
+			wantTagged:   map[string][]string{"apple": {tagKey}},
+			wantUntagged: map[string][]string{},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			kafkaClient := &mockKafkaClientAPI{
+				batches:     map[string][][]string{},
+				unprocessed: tt.giveUnprocessed,
+			}
+			secretsmanagerClient := &mockSecretsManagerClientAPI{
+				tagged:   map[string][]string{},
+				untagged: map[string][]string{},
+				err:      tt.giveTagErr,
+			}
+			svc := &Service{kafka: kafkaClient, secretsmanager: secretsmanagerClient}
+
+			cluster := &Cluster{
+				clusterInfo: &kafkatypes.ClusterInfo{
+					ClusterName: aws.String("example1"),
+					ClusterArn:  aws.String(clusterArn),
+				},
+				secretArnChangeSet: &SecretChangeSet{add: []string{"apple", "pear"}},
+				service:            svc,
+			}
+
+			err := updateClusterSecrets(&Fleet{}, cluster)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			td.Cmp(t, kafkaClient.batches, tt.wantBatches)
+			td.Cmp(t, secretsmanagerClient.tagged, tt.wantTagged)
+			td.Cmp(t, secretsmanagerClient.untagged, tt.wantUntagged)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/mikelorant/msk-secret-binder/internal/sliceutil"
+)
+
+type planFunc func(svc *Service, opts *Options, now time.Time) error
+
+func planClusters(svc *Service, opts *Options, now time.Time) error {
+	mappers, err := newMappers(svc, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, cluster := range svc.clusters {
+		resetCluster(cluster)
+
+		if err := scheduleCluster(cluster, opts.windows, opts.freezes, now); err != nil {
+			fmt.Printf("warning: %v\n", err)
+		}
+		mapSecretsToClusters(cluster, svc.secrets, mappers)
+		reconcileClusterSecrets(cluster)
+		filterChangeSet(cluster, &opts.filters)
+		vetoClusterSecrets(cluster, svc.secrets, opts.rules)
+		protectClusterSecrets(cluster, opts.protected)
+		cluster.quota = planQuota(cluster, opts.quotaWarn, opts.quotaLimit)
+	}
+
+	return nil
+}
+
+// planChangeSets plans clusters from fixed change sets, such as the inverse of
+// a previous run, rather than from secret mappings. Changes are limited to
+// those that still apply to the live associations.
+func planChangeSets(svc *Service, opts *Options, changeSets map[string]*SecretChangeSet, now time.Time) error {
+	mappers, err := newConfigMappers(opts)
+	if err != nil {
+		return err
+	}
+
+	// The mappings only explain the fixed changes, so a failing plugin must
+	// not block a rollback or resume.
+	if withPlugin, err := addPluginMapper(svc, pluginConfig(opts), mappers); err != nil {
+		fmt.Printf("warning: plugin skipped: %v\n", err)
+	} else {
+		mappers = withPlugin
+	}
+
+	for _, cluster := range svc.clusters {
+		resetCluster(cluster)
+
+		if err := scheduleCluster(cluster, opts.windows, opts.freezes, now); err != nil {
+			fmt.Printf("warning: %v\n", err)
+		}
+		mapSecretsToClusters(cluster, svc.secrets, mappers)
+
+		cs := &SecretChangeSet{
+			add:       []string{},
+			remove:    []string{},
+			unmanaged: []string{},
+		}
+		if planned, ok := changeSets[aws.ToString(cluster.clusterInfo.ClusterArn)]; ok && !cluster.ignored {
+			cs.add = sliceutil.Diff(sliceutil.Diff(planned.add, cluster.assosciatedSecretArnList), cluster.ignoredSecretArnList)
+			cs.remove = sliceutil.Diff(sliceutil.Intersect(planned.remove, cluster.assosciatedSecretArnList), cluster.ignoredSecretArnList)
+		}
+		cluster.secretArnChangeSet = cs
+
+		filterChangeSet(cluster, &opts.filters)
+		vetoClusterSecrets(cluster, svc.secrets, opts.rules)
+		protectClusterSecrets(cluster, opts.protected)
+		cluster.quota = planQuota(cluster, opts.quotaWarn, opts.quotaLimit)
+	}
+
+	return nil
+}
+
+func resetCluster(cluster *Cluster) {
+	cluster.secretArnList = nil
+	cluster.ignoredSecretArnList = nil
+	cluster.ownedSecretArnList = nil
+	cluster.bindings = nil
+	cluster.ruleErrors = nil
+	cluster.secretArnChangeSet = nil
+	cluster.quota = nil
+	cluster.deferred = ""
+}
+
+func validatePlan(clusters []*Cluster, opts *Options) error {
+	if err := validateQuotas(clusters); err != nil {
+		return err
+	}
+
+	if opts.strict {
+		if err := validateProtected(clusters); err != nil {
+			return err
+		}
+	}
+
+	if err := validateLimits(clusters, opts.limits, opts.override); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func planFleet(fleet *Fleet, opts *Options, plan planFunc, now time.Time) error {
+	for _, svc := range fleet.services {
+		if err := plan(svc, opts, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replanClusters plans only the given clusters, leaving the rest of their
+// services as planned.
+func replanClusters(clusters []*Cluster, opts *Options, plan planFunc, now time.Time) error {
+	services := []*Service{}
+	byService := map[*Service][]*Cluster{}
+	for _, cluster := range clusters {
+		if _, ok := byService[cluster.service]; !ok {
+			services = append(services, cluster.service)
+		}
+		byService[cluster.service] = append(byService[cluster.service], cluster)
+	}
+
+	for _, svc := range services {
+		sub := *svc
+		sub.clusters = byService[svc]
+		if err := plan(&sub, opts, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printPlan(fleet *Fleet, opts *Options) error {
+	userListTagKey := ""
+	if opts.config != nil {
+		userListTagKey = opts.config.UserListTagKey
+	}
+
+	if fleet.multiAccount() {
+		printOverview(fleet.clusters(), true)
+		printBindings(findExistingBindings(fleet.clusters()))
+		for _, svc := range fleet.services {
+			unbound := findUnboundSecrets(svc.clusters, svc.secrets)
+			conflicts := findConflicts(svc.clusters)
+			problems := findDeclarationProblems(svc.clusters, svc.secrets, userListTagKey)
+			if len(unbound) == 0 && len(conflicts) == 0 && len(problems) == 0 {
+				continue
+			}
+
+			printRegion(svc.account, svc.region)
+			printUnboundSecrets(unbound)
+			printConflicts(conflicts)
+			printDeclarationProblems(problems)
+		}
+		printRuleErrors(fleet.clusters())
+		printChangeSet(fleet.clusters())
+
+		return nil
+	}
+
+	for _, svc := range fleet.services {
+		if len(fleet.services) > 1 {
+			printRegion("", svc.region)
+		}
+		printOverview(svc.clusters, false)
+		printBindings(findExistingBindings(svc.clusters))
+		printUnboundSecrets(findUnboundSecrets(svc.clusters, svc.secrets))
+		printConflicts(findConflicts(svc.clusters))
+		printDeclarationProblems(findDeclarationProblems(svc.clusters, svc.secrets, userListTagKey))
+		printRuleErrors(svc.clusters)
+		printChangeSet(svc.clusters)
+	}
+
+	return nil
+}
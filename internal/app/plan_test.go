@@ -0,0 +1,89 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanChangeSets(t *testing.T) {
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("example1"),
+			ClusterArn:  aws.String("cluster1"),
+		},
+		assosciatedSecretArnList: []string{"apple", "pear"},
+	}
+
+	svc := &Service{
+		clusters: []*Cluster{cluster},
+	}
+
+	opts := &Options{
+		protected:  []string{"pear"},
+		quotaWarn:  900,
+		quotaLimit: 1000,
+	}
+
+	inverse := map[string]*SecretChangeSet{
+		"cluster1": {
+			add:    []string{"lemon", "apple"},
+			remove: []string{"orange", "pear"},
+		},
+	}
+
+	err := planChangeSets(svc, opts, inverse, time.Now())
+	assert.NoError(t, err)
+	td.Cmp(t, cluster.secretArnChangeSet, &SecretChangeSet{
+		add:       []string{"lemon"},
+		remove:    []string{},
+		unmanaged: []string{},
+		protected: []string{"pear"},
+	})
+	td.Cmp(t, cluster.quota, &Quota{used: 3, limit: 1000, status: QuotaOK})
+}
+
+func TestPlanChangeSetsPlugin(t *testing.T) {
+	const response = `cat >/dev/null; echo '{"version":1,"bindings":[{"cluster_arn":"cluster1","secret_arn":"secret1","rule":"catalog"}]}'`
+
+	tests := []struct {
+		name         string
+		give         string
+		wantBindings map[string]string
+	}{
+		{
+			name:         "plugin",
+			give:         response,
+			wantBindings: map[string]string{"secret1": "plugin catalog"},
+		}, {
+			name:         "plugin_fails",
+			give:         "cat >/dev/null; exit 1",
+			wantBindings: map[string]string{"secret2": "tag Cluster"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := testPluginService()
+			opts := &Options{
+				config: &Config{
+					Plugin: &PluginConfig{Command: []string{"sh", "-c", tt.give}, Mode: _pluginModeReplace, Timeout: 5 * time.Second},
+				},
+			}
+
+			inverse := map[string]*SecretChangeSet{
+				"cluster1": {add: []string{"secret1"}},
+			}
+
+			err := planChangeSets(svc, opts, inverse, time.Now())
+			assert.NoError(t, err)
+			td.Cmp(t, svc.clusters[0].bindings, tt.wantBindings)
+			td.Cmp(t, svc.clusters[0].secretArnChangeSet.add, []string{"secret1"})
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+const (
+	_pluginProtocolVersion = 1
+	_pluginModeAugment     = "augment"
+	_pluginModeReplace     = "replace"
+	_pluginTimeout         = 30 * time.Second
+	_pluginKillGrace       = 5 * time.Second
+)
+
+type PluginConfig struct {
+	Command []string      `yaml:"command"`
+	Mode    string        `yaml:"mode"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// PluginRequest is written to the plugin as JSON on stdin.
+type PluginRequest struct {
+	Version  int             `json:"version"`
+	Account  string          `json:"account,omitempty"`
+	Region   string          `json:"region"`
+	Clusters []PluginCluster `json:"clusters"`
+	Secrets  []PluginSecret  `json:"secrets"`
+}
+
+type PluginCluster struct {
+	Arn     string            `json:"arn"`
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Tags    map[string]string `json:"tags"`
+}
+
+type PluginSecret struct {
+	Arn  string            `json:"arn"`
+	Name string            `json:"name"`
+	Tags map[string]string `json:"tags"`
+}
+
+// PluginResponse is read from the plugin stdout. Every binding must refer to
+// a cluster and secret from the request.
+type PluginResponse struct {
+	Version  int             `json:"version"`
+	Bindings []PluginBinding `json:"bindings"`
+}
+
+type PluginBinding struct {
+	ClusterArn string `json:"cluster_arn"`
+	SecretArn  string `json:"secret_arn"`
+	Rule       string `json:"rule,omitempty"`
+}
+
+func (p *PluginConfig) validate() error {
+	if len(p.Command) == 0 || p.Command[0] == "" {
+		return fmt.Errorf("plugin command must not be empty")
+	}
+	if p.Mode != _pluginModeAugment && p.Mode != _pluginModeReplace {
+		return fmt.Errorf("invalid plugin mode %q: expected %v or %v", p.Mode, _pluginModeAugment, _pluginModeReplace)
+	}
+	if p.Timeout <= 0 {
+		return fmt.Errorf("plugin timeout must be positive")
+	}
+
+	return nil
+}
+
+// pluginMapper binds the secrets returned by the plugin for the cluster.
+type pluginMapper struct {
+	bindings map[string]map[string]string
+}
+
+func (m pluginMapper) Map(cluster *Cluster, secret secretsmanagertypes.SecretListEntry) (string, bool) {
+	rule, ok := m.bindings[aws.ToString(cluster.clusterInfo.ClusterArn)][aws.ToString(secret.ARN)]
+	return rule, ok
+}
+
+// addPluginMapper runs the plugin for the service and either adds its
+// bindings to the mappers or replaces them.
+func addPluginMapper(svc *Service, conf *PluginConfig, mappers []Mapper) ([]Mapper, error) {
+	if conf == nil {
+		return mappers, nil
+	}
+
+	resp, err := runPlugin(conf, newPluginRequest(svc))
+	if err != nil {
+		return nil, err
+	}
+
+	m := pluginMapper{bindings: map[string]map[string]string{}}
+	for _, b := range resp.Bindings {
+		if m.bindings[b.ClusterArn] == nil {
+			m.bindings[b.ClusterArn] = map[string]string{}
+		}
+		rule := "plugin"
+		if b.Rule != "" {
+			rule = "plugin " + b.Rule
+		}
+		m.bindings[b.ClusterArn][b.SecretArn] = rule
+	}
+
+	if conf.Mode == _pluginModeReplace {
+		return []Mapper{m}, nil
+	}
+
+	return append(mappers, m), nil
+}
+
+func newPluginRequest(svc *Service) *PluginRequest {
+	req := &PluginRequest{
+		Version:  _pluginProtocolVersion,
+		Account:  svc.account,
+		Region:   svc.region,
+		Clusters: []PluginCluster{},
+		Secrets:  []PluginSecret{},
+	}
+
+	for _, cluster := range svc.clusters {
+		pc := PluginCluster{
+			Arn:  aws.ToString(cluster.clusterInfo.ClusterArn),
+			Name: aws.ToString(cluster.clusterInfo.ClusterName),
+			Tags: cluster.clusterInfo.Tags,
+		}
+		if cluster.clusterInfo.CurrentBrokerSoftwareInfo != nil {
+			pc.Version = aws.ToString(cluster.clusterInfo.CurrentBrokerSoftwareInfo.KafkaVersion)
+		}
+		req.Clusters = append(req.Clusters, pc)
+	}
+
+	for _, secret := range svc.secrets {
+		tags := map[string]string{}
+		for _, tag := range secret.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		req.Secrets = append(req.Secrets, PluginSecret{
+			Arn:  aws.ToString(secret.ARN),
+			Name: aws.ToString(secret.Name),
+			Tags: tags,
+		})
+	}
+
+	return req
+}
+
+func runPlugin(conf *PluginConfig, req *PluginRequest) (*PluginResponse, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(conf.Command[0], conf.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("unable to run plugin: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	timer := time.NewTimer(conf.Timeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-done:
+	case <-timer.C:
+		// Killing only the plugin would leave its children holding stdout
+		// open and Wait blocked until they exit.
+		if err := killProcessGroup(cmd); err != nil {
+			return nil, fmt.Errorf("unable to stop plugin after %v: %w", conf.Timeout, err)
+		}
+		// A process that escaped the kill may still hold the pipes, so stop
+		// waiting for them after a grace period.
+		select {
+		case <-done:
+		case <-time.After(_pluginKillGrace):
+		}
+		return nil, fmt.Errorf("plugin timed out after %v", conf.Timeout)
+	}
+
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("unable to run plugin: %w: %v", err, msg)
+		}
+		return nil, fmt.Errorf("unable to run plugin: %w", err)
+	}
+
+	return parsePluginResponse(stdout.Bytes(), req)
+}
+
+func parsePluginResponse(data []byte, req *PluginRequest) (*PluginResponse, error) {
+	resp := &PluginResponse{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(resp); err != nil {
+		return nil, fmt.Errorf("unable to decode plugin response: %w", err)
+	}
+
+	if resp.Version != _pluginProtocolVersion {
+		return nil, fmt.Errorf("unsupported plugin response version %v: expected %v", resp.Version, _pluginProtocolVersion)
+	}
+
+	clusters := map[string]bool{}
+	for _, c := range req.Clusters {
+		clusters[c.Arn] = true
+	}
+	secrets := map[string]bool{}
+	for _, s := range req.Secrets {
+		secrets[s.Arn] = true
+	}
+
+	for i, b := range resp.Bindings {
+		if !clusters[b.ClusterArn] {
+			return nil, fmt.Errorf("plugin binding %v: unknown cluster %q", i+1, b.ClusterArn)
+		}
+		if !secrets[b.SecretArn] {
+			return nil, fmt.Errorf("plugin binding %v: unknown secret %q", i+1, b.SecretArn)
+		}
+	}
+
+	return resp, nil
+}
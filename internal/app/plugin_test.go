@@ -0,0 +1,146 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPluginService() *Service {
+	return &Service{
+		region: "us-east-1",
+		clusters: []*Cluster{
+			{
+				clusterInfo: &kafkatypes.ClusterInfo{
+					ClusterArn:  aws.String("cluster1"),
+					ClusterName: aws.String("payments"),
+				},
+			},
+		},
+		secrets: []secretsmanagertypes.SecretListEntry{
+			{ARN: aws.String("secret1"), Name: aws.String("AmazonMSK_alice")},
+			{
+				ARN:  aws.String("secret2"),
+				Name: aws.String("AmazonMSK_bob"),
+				Tags: []secretsmanagertypes.Tag{{Key: aws.String("Cluster"), Value: aws.String("payments")}},
+			},
+		},
+	}
+}
+
+func TestParsePluginResponse(t *testing.T) {
+	req := newPluginRequest(testPluginService())
+
+	tests := []struct {
+		name    string
+		give    string
+		want    *PluginResponse
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			give: `{"version":1,"bindings":[{"cluster_arn":"cluster1","secret_arn":"secret1","rule":"catalog"}]}`,
+			want: &PluginResponse{
+				Version:  1,
+				Bindings: []PluginBinding{{ClusterArn: "cluster1", SecretArn: "secret1", Rule: "catalog"}},
+			},
+		}, {
+			name:    "version",
+			give:    `{"version":2,"bindings":[]}`,
+			wantErr: true,
+		}, {
+			name:    "unknown_field",
+			give:    `{"version":1,"binding":[]}`,
+			wantErr: true,
+		}, {
+			name:    "unknown_cluster",
+			give:    `{"version":1,"bindings":[{"cluster_arn":"cluster2","secret_arn":"secret1"}]}`,
+			wantErr: true,
+		}, {
+			name:    "unknown_secret",
+			give:    `{"version":1,"bindings":[{"cluster_arn":"cluster1","secret_arn":"secret3"}]}`,
+			wantErr: true,
+		}, {
+			name:    "invalid",
+			give:    `bindings`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePluginResponse([]byte(tt.give), req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestAddPluginMapper(t *testing.T) {
+	const response = `cat >/dev/null; echo '{"version":1,"bindings":[{"cluster_arn":"cluster1","secret_arn":"secret1","rule":"catalog"}]}'`
+
+	tests := []struct {
+		name         string
+		give         *PluginConfig
+		wantBindings map[string]string
+		wantErr      bool
+	}{
+		{
+			name:         "none",
+			wantBindings: map[string]string{"secret2": "tag Cluster"},
+		}, {
+			name: "augment",
+			give: &PluginConfig{Command: []string{"sh", "-c", response}, Mode: _pluginModeAugment, Timeout: 5 * time.Second},
+			wantBindings: map[string]string{
+				"secret1": "plugin catalog",
+				"secret2": "tag Cluster",
+			},
+		}, {
+			name:         "replace",
+			give:         &PluginConfig{Command: []string{"sh", "-c", response}, Mode: _pluginModeReplace, Timeout: 5 * time.Second},
+			wantBindings: map[string]string{"secret1": "plugin catalog"},
+		}, {
+			name:    "failure",
+			give:    &PluginConfig{Command: []string{"sh", "-c", "echo catalog unavailable >&2; exit 1"}, Mode: _pluginModeAugment, Timeout: 5 * time.Second},
+			wantErr: true,
+		}, {
+			name:    "timeout",
+			give:    &PluginConfig{Command: []string{"sleep", "5"}, Mode: _pluginModeAugment, Timeout: 50 * time.Millisecond},
+			wantErr: true,
+		}, {
+			name:    "timeout_children",
+			give:    &PluginConfig{Command: []string{"sh", "-c", "sleep 5; echo"}, Mode: _pluginModeAugment, Timeout: 50 * time.Millisecond},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := testPluginService()
+
+			start := time.Now()
+			mappers, err := addPluginMapper(svc, tt.give, []Mapper{tagMapper{}})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Less(t, time.Since(start), 2*time.Second)
+				return
+			}
+			assert.Nil(t, err)
+
+			cluster := svc.clusters[0]
+			mapSecretsToClusters(cluster, svc.secrets, mappers)
+			td.Cmp(t, cluster.bindings, tt.wantBindings)
+		})
+	}
+}
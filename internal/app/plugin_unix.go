@@ -0,0 +1,18 @@
+//go:build !windows
+
+package app
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the plugin in its own process group so a timeout
+// also stops any processes the plugin started.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
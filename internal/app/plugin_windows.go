@@ -0,0 +1,21 @@
+//go:build windows
+
+package app
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup stops the plugin and the processes it started. Windows has
+// no process groups to signal, so taskkill walks the process tree instead.
+func killProcessGroup(cmd *exec.Cmd) error {
+	pid := strconv.Itoa(cmd.Process.Pid)
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", pid).Run(); err != nil {
+		return cmd.Process.Kill()
+	}
+
+	return nil
+}
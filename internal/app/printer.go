@@ -2,25 +2,110 @@ package app
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/fatih/color"
 	"github.com/rodaine/table"
 )
 
-func printOverview(clusters []*Cluster) error {
+func printRegion(account, region string) error {
+	heading := fmt.Sprintf("Region %v", region)
+	if account != "" {
+		heading = fmt.Sprintf("Account %v %v", account, heading)
+	}
+
+	fmt.Println(color.New(color.FgCyan, color.Bold).Sprint(heading))
+	fmt.Println()
+
+	return nil
+}
+
+func printOverview(clusters []*Cluster, accounts bool) error {
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 
-	tbl := table.New("Cluster Name", "Version", "Assosciated", "Additions", "Removals")
+	columns := []interface{}{"Cluster Name", "Version", "Assosciated", "Additions", "Removals", "Ignored", "Quota", "Status"}
+	if accounts {
+		columns = append([]interface{}{"Account", "Region"}, columns...)
+	}
+
+	tbl := table.New(columns...)
 	tbl.WithHeaderFormatter(headerFmt)
 
 	for _, cluster := range clusters {
-		tbl.AddRow(
+		row := []interface{}{}
+		if accounts {
+			row = append(row, cluster.service.account, cluster.service.region)
+		}
+		tbl.AddRow(append(row,
 			aws.ToString(cluster.clusterInfo.ClusterName),
 			aws.ToString(cluster.clusterInfo.CurrentBrokerSoftwareInfo.KafkaVersion),
 			len(cluster.assosciatedSecretArnList),
 			len(cluster.secretArnChangeSet.add),
 			len(cluster.secretArnChangeSet.remove),
+			len(cluster.ignoredSecretArnList),
+			cluster.quota,
+			clusterStatus(cluster),
+		)...)
+	}
+	tbl.Print()
+
+	fmt.Println()
+
+	return nil
+}
+
+func clusterLabel(cluster *Cluster) string {
+	name := aws.ToString(cluster.clusterInfo.ClusterName)
+	if cluster.service == nil {
+		return name
+	}
+
+	return formatClusterLabel(name, cluster.service.account, cluster.service.region)
+}
+
+// formatClusterLabel adds the account and region to the cluster name when
+// reconciling several accounts, where the name alone is ambiguous.
+func formatClusterLabel(name, account, region string) string {
+	if account == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%v (%v/%v)", name, account, region)
+}
+
+func clusterStatus(cluster *Cluster) string {
+	switch {
+	case cluster.ignored:
+		return "ignored"
+	case cluster.deferred != "":
+		return fmt.Sprintf("deferred (%v)", cluster.deferred)
+	}
+
+	return ""
+}
+
+func printUnboundSecrets(unbound []*UnboundSecret) error {
+	if len(unbound) == 0 {
+		return nil
+	}
+
+	headerFmt := color.New(color.FgYellow, color.Underline).SprintfFunc()
+
+	fmt.Println("Unbound secrets")
+	fmt.Println()
+
+	tbl := table.New("Secret Name", "Cluster Tag", "Closest Cluster", "Near Miss Tags", "Selector Error")
+	tbl.WithHeaderFormatter(headerFmt)
+
+	for _, us := range unbound {
+		tbl.AddRow(
+			us.name,
+			us.tagValue,
+			us.closestCluster,
+			strings.Join(quoteAll(us.nearMissTags), ", "),
+			us.selectorErr,
 		)
 	}
 	tbl.Print()
@@ -30,15 +115,220 @@ func printOverview(clusters []*Cluster) error {
 	return nil
 }
 
+func printBindings(bindings []*Binding) error {
+	if len(bindings) == 0 {
+		return nil
+	}
+
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+
+	fmt.Println("Existing bindings")
+	fmt.Println()
+
+	tbl := table.New("Cluster Name", "Secret Name", "Rule")
+	tbl.WithHeaderFormatter(headerFmt)
+
+	for _, b := range bindings {
+		tbl.AddRow(clusterLabel(b.cluster), b.name, b.rule)
+	}
+	tbl.Print()
+
+	fmt.Println()
+
+	return nil
+}
+
+func printConflicts(conflicts []*Conflict) error {
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	headerFmt := color.New(color.FgYellow, color.Underline).SprintfFunc()
+
+	fmt.Println("Conflicting bindings")
+	fmt.Println()
+
+	tbl := table.New("Secret Name", "Declared By", "Mapped To")
+	tbl.WithHeaderFormatter(headerFmt)
+
+	for _, c := range conflicts {
+		tbl.AddRow(
+			c.name,
+			strings.Join(c.declaredBy, ", "),
+			strings.Join(c.mappedTo, ", "),
+		)
+	}
+	tbl.Print()
+
+	fmt.Println()
+
+	return nil
+}
+
+func printDeclarationProblems(problems []*DeclarationProblem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	headerFmt := color.New(color.FgYellow, color.Underline).SprintfFunc()
+
+	fmt.Println("Declared user problems")
+	fmt.Println()
+
+	tbl := table.New("Cluster Name", "Pattern", "Problem")
+	tbl.WithHeaderFormatter(headerFmt)
+
+	for _, p := range problems {
+		tbl.AddRow(clusterLabel(p.cluster), p.pattern, p.problem)
+	}
+	tbl.Print()
+
+	fmt.Println()
+
+	return nil
+}
+
+func printRuleErrors(clusters []*Cluster) error {
+	headerFmt := color.New(color.FgYellow, color.Underline).SprintfFunc()
+
+	tbl := table.New("Cluster Name", "Rule", "Secrets", "Error")
+	tbl.WithHeaderFormatter(headerFmt)
+
+	rows := 0
+	for _, cluster := range clusters {
+		names := []string{}
+		for name := range cluster.ruleErrors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			re := cluster.ruleErrors[name]
+			tbl.AddRow(clusterLabel(cluster), re.rule, len(re.secrets), re.err)
+			rows++
+		}
+	}
+
+	if rows == 0 {
+		return nil
+	}
+
+	fmt.Println("Rule errors")
+	fmt.Println()
+	tbl.Print()
+	fmt.Println()
+
+	return nil
+}
+
+func quoteAll(strs []string) []string {
+	quoted := []string{}
+	for _, s := range strs {
+		quoted = append(quoted, fmt.Sprintf("%q", s))
+	}
+
+	return quoted
+}
+
 func printChangeSet(clusters []*Cluster) error {
 	for _, cluster := range clusters {
-		c := len(cluster.secretArnChangeSet.add) + len(cluster.secretArnChangeSet.remove)
+		cs := cluster.secretArnChangeSet
+		c := len(cs.add) + len(cs.remove) + len(cs.unmanaged) + len(cs.adopt) + len(cs.protected) + len(cs.vetoed)
 		if c > 0 {
-			fmt.Println(aws.ToString(cluster.clusterInfo.ClusterName))
-			fmt.Print(cluster.secretArnChangeSet)
+			fmt.Println(clusterLabel(cluster))
+			fmt.Print(cluster.secretArnChangeSet.format(cluster.bindings))
 			fmt.Println()
 		}
 	}
 
 	return nil
 }
+
+func printWaves(waves []*Wave) error {
+	if len(waves) < 2 {
+		return nil
+	}
+
+	fmt.Println("Rollout waves")
+	for i, wave := range waves {
+		names := []string{}
+		for _, cluster := range wave.clusters {
+			names = append(names, clusterLabel(cluster))
+		}
+		fmt.Printf("%v. %v: %v\n", i+1, wave.name, strings.Join(names, ", "))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func printVerification(mismatches []*Verification) error {
+	for _, v := range mismatches {
+		fmt.Println(clusterLabel(v.cluster))
+		for _, arn := range v.missing {
+			fmt.Printf("!%v (missing)\n", arn)
+		}
+		for _, arn := range v.unexpected {
+			fmt.Printf("!%v (unexpected)\n", arn)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func printDrift(drifts []*Drift) error {
+	for _, d := range drifts {
+		fmt.Println(clusterLabel(d.cluster))
+		for _, arn := range d.added {
+			fmt.Printf("+%v (associated since plan)\n", arn)
+		}
+		for _, arn := range d.removed {
+			fmt.Printf("-%v (disassociated since plan)\n", arn)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func printCheckpoint(cp *Checkpoint) error {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+
+	tbl := table.New("Cluster Name", "Batches", "Succeeded", "Partial", "Failed", "Completed")
+	tbl.WithHeaderFormatter(headerFmt)
+
+	unprocessed := []string{}
+	for _, cc := range cp.Clusters {
+		label := formatClusterLabel(cc.ClusterName, cc.Account, cc.Region)
+		results := map[string]int{}
+		for _, batch := range cc.Batches {
+			results[batch.Result.Status]++
+			for _, v := range batch.Result.Unprocessed {
+				unprocessed = append(unprocessed, fmt.Sprintf("%v: %v %v: %v", label, batch.Action, v.SecretArn, v.ErrorMessage))
+			}
+			if batch.Result.Error != "" {
+				unprocessed = append(unprocessed, fmt.Sprintf("%v: %v: %v", label, batch.Action, batch.Result.Error))
+			}
+		}
+		tbl.AddRow(
+			label,
+			len(cc.Batches),
+			results[_resultSucceeded],
+			results[_resultPartial],
+			results[_resultFailed],
+			cc.Completed,
+		)
+	}
+	tbl.Print()
+	fmt.Println()
+
+	for _, v := range unprocessed {
+		fmt.Println(v)
+	}
+	if len(unprocessed) > 0 {
+		fmt.Println()
+	}
+
+	return nil
+}
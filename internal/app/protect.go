@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+func protectClusterSecrets(cluster *Cluster, patterns []string) error {
+	patterns = append(append([]string{}, patterns...), cluster.protected...)
+
+	remove := []string{}
+	for _, arn := range cluster.secretArnChangeSet.remove {
+		if isProtectedSecret(arn, patterns) {
+			cluster.secretArnChangeSet.protected = append(cluster.secretArnChangeSet.protected, arn)
+			continue
+		}
+		remove = append(remove, arn)
+	}
+	cluster.secretArnChangeSet.remove = remove
+
+	return nil
+}
+
+func isProtectedSecret(arn string, patterns []string) bool {
+	name := secretName(arn)
+	for _, pattern := range patterns {
+		if pattern == arn {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateProtectedPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid protected pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func validateProtected(clusters []*Cluster) error {
+	protected := []string{}
+	for _, cluster := range clusters {
+		for _, arn := range cluster.secretArnChangeSet.protected {
+			protected = append(protected, fmt.Sprintf("%v (%v)", secretName(arn), clusterLabel(cluster)))
+		}
+	}
+
+	if len(protected) > 0 {
+		return fmt.Errorf("plan removes protected secrets: %v", strings.Join(protected, ", "))
+	}
+
+	return nil
+}
+
+// secretName derives the secret name from its ARN by removing the random
+// six character suffix that secrets manager appends.
+func secretName(arn string) string {
+	i := strings.Index(arn, ":secret:")
+	if i == -1 {
+		return arn
+	}
+
+	name := arn[i+len(":secret:"):]
+	if j := strings.LastIndex(name, "-"); j != -1 && len(name)-j == 7 {
+		name = name[:j]
+	}
+
+	return name
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretName(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{
+			name: "arn",
+			give: "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_admin-123456",
+			want: "AmazonMSK_admin",
+		}, {
+			name: "arn_with_dashes",
+			give: "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_mirror-maker-234567",
+			want: "AmazonMSK_mirror-maker",
+		}, {
+			name: "arn_with_path",
+			give: "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_team/app-345678",
+			want: "AmazonMSK_team/app",
+		}, {
+			name: "not_arn",
+			give: "AmazonMSK_admin",
+			want: "AmazonMSK_admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := secretName(tt.give)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestProtectClusterSecrets(t *testing.T) {
+	tests := []struct {
+		name          string
+		give          []string
+		wantRemove    []string
+		wantProtected []string
+	}{
+		{
+			name:          "none",
+			give:          []string{},
+			wantRemove:    []string{},
+			wantProtected: nil,
+		}, {
+			name: "pattern",
+			give: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_admin-123456",
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments-234567",
+			},
+			wantRemove: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments-234567",
+			},
+			wantProtected: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_admin-123456",
+			},
+		}, {
+			name: "arn",
+			give: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_orders-345678",
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_mirrormaker_prd-456789",
+			},
+			wantRemove: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_orders-345678",
+			},
+			wantProtected: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_mirrormaker_prd-456789",
+			},
+		},
+	}
+
+	patterns := []string{
+		"AmazonMSK_admin*",
+		"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_mirrormaker_prd-456789",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &Cluster{
+				secretArnChangeSet: &SecretChangeSet{
+					remove: tt.give,
+				},
+			}
+
+			protectClusterSecrets(cluster, patterns)
+			td.Cmp(t, cluster.secretArnChangeSet.remove, tt.wantRemove)
+			td.Cmp(t, cluster.secretArnChangeSet.protected, tt.wantProtected)
+		})
+	}
+}
+
+func TestValidateProtectedPatterns(t *testing.T) {
+	assert.NoError(t, validateProtectedPatterns([]string{"AmazonMSK_admin*", "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_admin-123456"}))
+	assert.EqualError(t, validateProtectedPatterns([]string{"AmazonMSK_admin", "AmazonMSK_[admin"}), `invalid protected pattern "AmazonMSK_[admin": syntax error in pattern`)
+}
+
+func TestValidateProtected(t *testing.T) {
+	clusters := []*Cluster{
+		{
+			clusterInfo:        &kafkatypes.ClusterInfo{ClusterName: aws.String("example1")},
+			secretArnChangeSet: &SecretChangeSet{},
+		},
+	}
+	assert.NoError(t, validateProtected(clusters))
+
+	clusters = append(clusters, &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("example2")},
+		secretArnChangeSet: &SecretChangeSet{
+			protected: []string{"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_admin-123456"},
+		},
+	})
+	assert.EqualError(t, validateProtected(clusters), "plan removes protected secrets: AmazonMSK_admin (example2)")
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	_scramSecretQuotaLimit = 1000
+	_scramSecretQuotaWarn  = 900
+)
+
+type QuotaStatus int
+
+const (
+	QuotaOK QuotaStatus = iota
+	QuotaWarn
+	QuotaExceeded
+)
+
+type Quota struct {
+	used   int
+	limit  int
+	status QuotaStatus
+}
+
+func (q Quota) String() string {
+	switch q.status {
+	case QuotaWarn:
+		return fmt.Sprintf("%v/%v (warn)", q.used, q.limit)
+	case QuotaExceeded:
+		return fmt.Sprintf("%v/%v (exceeded)", q.used, q.limit)
+	}
+	return fmt.Sprintf("%v/%v", q.used, q.limit)
+}
+
+func planQuota(cluster *Cluster, warn, limit int) *Quota {
+	used := len(cluster.assosciatedSecretArnList) +
+		len(cluster.secretArnChangeSet.add) -
+		len(cluster.secretArnChangeSet.remove)
+
+	status := QuotaOK
+	switch {
+	case used > limit:
+		status = QuotaExceeded
+	case used >= warn:
+		status = QuotaWarn
+	}
+
+	return &Quota{
+		used:   used,
+		limit:  limit,
+		status: status,
+	}
+}
+
+func validateQuotas(clusters []*Cluster) error {
+	exceeded := []string{}
+	for _, cluster := range clusters {
+		if cluster.quota != nil && cluster.quota.status == QuotaExceeded {
+			exceeded = append(exceeded, clusterLabel(cluster))
+		}
+	}
+
+	if len(exceeded) > 0 {
+		return fmt.Errorf("scram secret quota exceeded for %v", strings.Join(exceeded, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanQuota(t *testing.T) {
+	tests := []struct {
+		name           string
+		giveAssociated []string
+		giveChangeSet  *SecretChangeSet
+		want           *Quota
+	}{
+		{
+			name:           "ok",
+			giveAssociated: []string{"apple", "pear"},
+			giveChangeSet: &SecretChangeSet{
+				add: []string{"orange"},
+			},
+			want: &Quota{used: 3, limit: 5, status: QuotaOK},
+		}, {
+			name:           "warn",
+			giveAssociated: []string{"apple", "pear", "orange"},
+			giveChangeSet: &SecretChangeSet{
+				add: []string{"lemon"},
+			},
+			want: &Quota{used: 4, limit: 5, status: QuotaWarn},
+		}, {
+			name:           "at_limit",
+			giveAssociated: []string{"apple", "pear", "orange", "lemon"},
+			giveChangeSet: &SecretChangeSet{
+				add: []string{"peach"},
+			},
+			want: &Quota{used: 5, limit: 5, status: QuotaWarn},
+		}, {
+			name:           "exceeded",
+			giveAssociated: []string{"apple", "pear", "orange", "lemon"},
+			giveChangeSet: &SecretChangeSet{
+				add: []string{"peach", "coconut"},
+			},
+			want: &Quota{used: 6, limit: 5, status: QuotaExceeded},
+		}, {
+			name:           "removals_offset_additions",
+			giveAssociated: []string{"apple", "pear", "orange", "lemon"},
+			giveChangeSet: &SecretChangeSet{
+				add:    []string{"peach", "coconut"},
+				remove: []string{"apple", "pear"},
+			},
+			want: &Quota{used: 4, limit: 5, status: QuotaWarn},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &Cluster{
+				assosciatedSecretArnList: tt.giveAssociated,
+				secretArnChangeSet:       tt.giveChangeSet,
+			}
+
+			got := planQuota(cluster, 4, 5)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestValidateQuotas(t *testing.T) {
+	cluster := func(name string, status QuotaStatus) *Cluster {
+		return &Cluster{
+			clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String(name)},
+			quota:       &Quota{status: status},
+		}
+	}
+
+	tests := []struct {
+		name string
+		give []*Cluster
+		err  string
+	}{
+		{
+			name: "ok",
+			give: []*Cluster{
+				cluster("example1", QuotaOK),
+				cluster("example2", QuotaWarn),
+			},
+		}, {
+			name: "exceeded",
+			give: []*Cluster{
+				cluster("example1", QuotaOK),
+				cluster("example2", QuotaExceeded),
+				cluster("example3", QuotaExceeded),
+			},
+			err: "scram secret quota exceeded for example2, example3",
+		}, {
+			name: "accounts",
+			give: []*Cluster{
+				{
+					clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("example1")},
+					service:     &Service{account: "111111111111", region: "ap-southeast-2"},
+					quota:       &Quota{status: QuotaExceeded},
+				}, {
+					clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("example1")},
+					service:     &Service{account: "222222222222", region: "ap-southeast-2"},
+					quota:       &Quota{status: QuotaExceeded},
+				},
+			},
+			err: "scram secret quota exceeded for example1 (111111111111/ap-southeast-2), example1 (222222222222/ap-southeast-2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateQuotas(tt.give)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	_allRegions = "all"
+)
+
+type EC2ClientAPI interface {
+	DescribeRegions(context.Context, *ec2.DescribeRegionsInput, ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error)
+}
+
+func resolveRegions(cfg aws.Config, regions string) ([]string, error) {
+	switch regions {
+	case "":
+		if cfg.Region == "" {
+			return nil, fmt.Errorf("no region configured")
+		}
+		return []string{cfg.Region}, nil
+	case _allRegions:
+		return listRegions(ec2.NewFromConfig(cfg))
+	}
+
+	return parseList(regions), nil
+}
+
+// listRegions returns the regions enabled for the account. DescribeRegions
+// omits regions that have not been opted in unless all regions are requested.
+func listRegions(cl EC2ClientAPI) ([]string, error) {
+	output, err := cl.DescribeRegions(context.TODO(), &ec2.DescribeRegionsInput{})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return nil, fmt.Errorf("unable to list regions: %v", apiErr.ErrorMessage())
+		}
+		return nil, fmt.Errorf("unable to list regions: %w", err)
+	}
+
+	names := []string{}
+	for _, region := range output.Regions {
+		names = append(names, aws.ToString(region.RegionName))
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
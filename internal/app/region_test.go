@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEC2ClientAPI struct {
+	regions []string
+	err     error
+}
+
+func (m mockEC2ClientAPI) DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	regions := []ec2types.Region{}
+	for _, name := range m.regions {
+		regions = append(regions, ec2types.Region{RegionName: aws.String(name)})
+	}
+
+	return &ec2.DescribeRegionsOutput{Regions: regions}, nil
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want []string
+	}{
+		{
+			name: "single",
+			give: "us-east-1",
+			want: []string{"us-east-1"},
+		}, {
+			name: "multiple",
+			give: "us-east-1, eu-west-1",
+			want: []string{"us-east-1", "eu-west-1"},
+		}, {
+			name: "empty_entries",
+			give: "us-east-1,,",
+			want: []string{"us-east-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, parseList(tt.give), tt.want)
+		})
+	}
+}
+
+func TestListRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    mockEC2ClientAPI
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "sorted",
+			give: mockEC2ClientAPI{regions: []string{"us-east-1", "ap-southeast-2", "eu-west-1"}},
+			want: []string{"ap-southeast-2", "eu-west-1", "us-east-1"},
+		}, {
+			name:    "error",
+			give:    mockEC2ClientAPI{err: errors.New("denied")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listRegions(tt.give)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestNewFleet(t *testing.T) {
+	fleet, err := newFleet(aws.Config{Region: "us-east-1"}, aws.Config{Region: "us-east-1"}, &Options{regions: "eu-west-1,ap-southeast-2", config: defaultConfig()}, "operator")
+	assert.Nil(t, err)
+	assert.Len(t, fleet.services, 2)
+	assert.Equal(t, "eu-west-1", fleet.services[0].region)
+	assert.Equal(t, "ap-southeast-2", fleet.services[1].region)
+
+	fleet, err = newFleet(aws.Config{Region: "us-east-1"}, aws.Config{Region: "us-east-1"}, &Options{config: defaultConfig()}, "operator")
+	assert.Nil(t, err)
+	assert.Len(t, fleet.services, 1)
+	assert.Equal(t, "us-east-1", fleet.services[0].region)
+
+	_, err = newFleet(aws.Config{}, aws.Config{}, &Options{config: defaultConfig()}, "operator")
+	assert.Error(t, err)
+}
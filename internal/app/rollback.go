@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/mikelorant/msk-secret-binder/internal/sliceutil"
+)
+
+func runRollback(args []string) error {
+	opts, err := parseOptions("rollback", args)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readJournal(opts.journal)
+	if err != nil {
+		return err
+	}
+
+	runID, entries, err := selectRun(entries, opts.run)
+	if err != nil {
+		return err
+	}
+
+	inverse := inverseChangeSets(entries)
+
+	if err := checkNoCheckpoint(opts.checkpoint); err != nil {
+		return err
+	}
+
+	fleet, spin, err := prepareRun(opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Roll back run %v.\n", runID)
+	fmt.Println()
+
+	keep := func(cluster *Cluster) bool {
+		_, ok := inverse[aws.ToString(cluster.clusterInfo.ClusterArn)]
+		return ok
+	}
+	if err := retrieveData(fleet, opts, spin, keep); err != nil {
+		return err
+	}
+
+	for arn := range inverse {
+		if !isKnownCluster(fleet.clusters(), arn) {
+			fmt.Printf("warning: cluster %v no longer exists\n", arn)
+		}
+	}
+
+	plan := func(svc *Service, opts *Options, now time.Time) error {
+		return planChangeSets(svc, opts, inverse, now)
+	}
+
+	return confirmAndApply(fleet, opts, spin, plan)
+}
+
+func selectRun(entries []*JournalEntry, runID string) (string, []*JournalEntry, error) {
+	if runID == "" {
+		if len(entries) == 0 {
+			return "", nil, fmt.Errorf("no runs recorded in journal")
+		}
+		runID = entries[len(entries)-1].RunID
+	}
+
+	selected := []*JournalEntry{}
+	for _, entry := range entries {
+		if entry.RunID == runID {
+			selected = append(selected, entry)
+		}
+	}
+
+	if len(selected) == 0 {
+		return "", nil, fmt.Errorf("run %v not found in journal", runID)
+	}
+
+	return runID, selected, nil
+}
+
+func inverseChangeSets(entries []*JournalEntry) map[string]*SecretChangeSet {
+	inverse := map[string]*SecretChangeSet{}
+
+	for _, entry := range entries {
+		if entry.Result.Status == _resultFailed {
+			continue
+		}
+
+		cs, ok := inverse[entry.ClusterArn]
+		if !ok {
+			cs = &SecretChangeSet{
+				add:    []string{},
+				remove: []string{},
+			}
+			inverse[entry.ClusterArn] = cs
+		}
+
+		unprocessed := []string{}
+		for _, v := range entry.Result.Unprocessed {
+			unprocessed = append(unprocessed, v.SecretArn)
+		}
+
+		for _, arn := range sliceutil.Diff(entry.SecretArns, unprocessed) {
+			switch entry.Action {
+			case _actionAssociate:
+				if sliceutil.Contains(cs.add, arn) {
+					cs.add = sliceutil.Diff(cs.add, []string{arn})
+				} else {
+					cs.remove = append(cs.remove, arn)
+				}
+			case _actionDisassociate:
+				if sliceutil.Contains(cs.remove, arn) {
+					cs.remove = sliceutil.Diff(cs.remove, []string{arn})
+				} else {
+					cs.add = append(cs.add, arn)
+				}
+			}
+		}
+	}
+
+	return inverse
+}
+
+func isKnownCluster(clusters []*Cluster, arn string) bool {
+	for _, cluster := range clusters {
+		if aws.ToString(cluster.clusterInfo.ClusterArn) == arn {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectRun(t *testing.T) {
+	entries := []*JournalEntry{
+		{RunID: "run1", ClusterName: "example1"},
+		{RunID: "run2", ClusterName: "example1"},
+		{RunID: "run2", ClusterName: "example2"},
+	}
+
+	tests := []struct {
+		name        string
+		give        string
+		want        string
+		wantEntries []*JournalEntry
+		err         string
+	}{
+		{
+			name:        "latest",
+			give:        "",
+			want:        "run2",
+			wantEntries: entries[1:],
+		}, {
+			name:        "specific",
+			give:        "run1",
+			want:        "run1",
+			wantEntries: entries[:1],
+		}, {
+			name: "unknown",
+			give: "run3",
+			err:  "run run3 not found in journal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotEntries, err := selectRun(entries, tt.give)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			td.Cmp(t, got, tt.want)
+			td.Cmp(t, gotEntries, tt.wantEntries)
+		})
+	}
+
+	_, _, err := selectRun([]*JournalEntry{}, "")
+	assert.EqualError(t, err, "no runs recorded in journal")
+}
+
+func TestInverseChangeSets(t *testing.T) {
+	entries := []*JournalEntry{
+		{
+			ClusterArn: "cluster1",
+			Action:     "associate",
+			SecretArns: []string{"apple", "pear", "orange"},
+			Result: JournalResult{
+				Status:      "partial",
+				Unprocessed: []JournalUnprocessed{{SecretArn: "orange"}},
+			},
+		}, {
+			ClusterArn: "cluster1",
+			Action:     "disassociate",
+			SecretArns: []string{"lemon", "pear"},
+			Result:     JournalResult{Status: "succeeded"},
+		}, {
+			ClusterArn: "cluster2",
+			Action:     "associate",
+			SecretArns: []string{"peach"},
+			Result:     JournalResult{Status: "failed", Error: "access denied"},
+		},
+	}
+
+	want := map[string]*SecretChangeSet{
+		"cluster1": {
+			add:    []string{"lemon"},
+			remove: []string{"apple"},
+		},
+	}
+
+	td.Cmp(t, inverseChangeSets(entries), want)
+}
@@ -0,0 +1,251 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/google/cel-go/cel"
+
+	"github.com/mikelorant/msk-secret-binder/internal/sliceutil"
+)
+
+const (
+	_ruleActionBind = "bind"
+	_ruleActionVeto = "veto"
+)
+
+type RuleConfig struct {
+	Name       string `yaml:"name"`
+	Action     string `yaml:"action"`
+	Expression string `yaml:"expression"`
+}
+
+// Rule is a compiled CEL expression evaluated per cluster and secret pair.
+// Bind rules add bindings and veto rules drop planned changes.
+type Rule struct {
+	name    string
+	action  string
+	program cel.Program
+}
+
+type Veto struct {
+	arn    string
+	action string
+	rule   string
+}
+
+// RuleError is a rule that failed to evaluate for some of the secrets of a
+// cluster, usually by reading a tag the secret does not have.
+type RuleError struct {
+	rule    string
+	secrets []string
+	err     string
+}
+
+func newRuleEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("cluster", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("secret", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("action", cel.StringType),
+	)
+}
+
+func newRules(configs []RuleConfig) ([]*Rule, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	env, err := newRuleEnv()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create rule environment: %w", err)
+	}
+
+	names := map[string]bool{}
+	rules := []*Rule{}
+	for i, rc := range configs {
+		if rc.Name == "" {
+			return nil, fmt.Errorf("rule %v: name must not be empty", i+1)
+		}
+		if names[rc.Name] {
+			return nil, fmt.Errorf("rule %v: duplicate name %q", i+1, rc.Name)
+		}
+		names[rc.Name] = true
+
+		if rc.Action != _ruleActionBind && rc.Action != _ruleActionVeto {
+			return nil, fmt.Errorf("rule %v: invalid action %q: expected %v or %v", rc.Name, rc.Action, _ruleActionBind, _ruleActionVeto)
+		}
+
+		ast, issues := env.Compile(rc.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("rule %v: %w", rc.Name, issues.Err())
+		}
+		if !cel.BoolType.IsAssignableType(ast.OutputType()) {
+			return nil, fmt.Errorf("rule %v: expression returns %v instead of bool", rc.Name, ast.OutputType())
+		}
+
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("rule %v: %w", rc.Name, err)
+		}
+
+		rules = append(rules, &Rule{
+			name:    rc.Name,
+			action:  rc.Action,
+			program: program,
+		})
+	}
+
+	return rules, nil
+}
+
+func (r *Rule) eval(cluster *Cluster, secret secretsmanagertypes.SecretListEntry, action string) (bool, error) {
+	tags := map[string]string{}
+	for _, tag := range secret.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	clusterTags := cluster.clusterInfo.Tags
+	if clusterTags == nil {
+		clusterTags = map[string]string{}
+	}
+
+	version := ""
+	if cluster.clusterInfo.CurrentBrokerSoftwareInfo != nil {
+		version = aws.ToString(cluster.clusterInfo.CurrentBrokerSoftwareInfo.KafkaVersion)
+	}
+
+	out, _, err := r.program.Eval(map[string]interface{}{
+		"cluster": map[string]interface{}{
+			"name":    aws.ToString(cluster.clusterInfo.ClusterName),
+			"arn":     aws.ToString(cluster.clusterInfo.ClusterArn),
+			"version": version,
+			"tags":    clusterTags,
+		},
+		"secret": map[string]interface{}{
+			"name": aws.ToString(secret.Name),
+			"arn":  aws.ToString(secret.ARN),
+			"tags": tags,
+		},
+		"action": action,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("rule %v returned %v instead of bool", r.name, out.Type())
+	}
+
+	return result, nil
+}
+
+// recordRuleError keeps the first error of each rule and the secrets it failed
+// for so the plan can report them.
+func recordRuleError(cluster *Cluster, rule string, secret secretsmanagertypes.SecretListEntry, err error) {
+	if cluster.ruleErrors == nil {
+		cluster.ruleErrors = map[string]*RuleError{}
+	}
+
+	re, ok := cluster.ruleErrors[rule]
+	if !ok {
+		re = &RuleError{rule: rule, err: err.Error()}
+		cluster.ruleErrors[rule] = re
+	}
+
+	name := aws.ToString(secret.Name)
+	if !sliceutil.Contains(re.secrets, name) {
+		re.secrets = append(re.secrets, name)
+	}
+}
+
+// ruleMapper binds secrets for which a bind rule is true. Rules that fail to
+// evaluate do not bind and are reported in the plan.
+type ruleMapper struct {
+	rules []*Rule
+}
+
+func (m ruleMapper) Map(cluster *Cluster, secret secretsmanagertypes.SecretListEntry) (string, bool) {
+	for _, r := range m.rules {
+		if r.action != _ruleActionBind {
+			continue
+		}
+		ok, err := r.eval(cluster, secret, _ruleActionBind)
+		if err != nil {
+			recordRuleError(cluster, r.name, secret, err)
+			continue
+		}
+		if ok {
+			return "rule " + r.name, true
+		}
+	}
+
+	return "", false
+}
+
+// vetoClusterSecrets drops planned changes for which a veto rule is true. A
+// veto rule that fails to evaluate vetoes the change so errors fail safe, and
+// the error is reported in the plan.
+func vetoClusterSecrets(cluster *Cluster, secrets []secretsmanagertypes.SecretListEntry, rules []*Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	entries := map[string]secretsmanagertypes.SecretListEntry{}
+	for _, secret := range secrets {
+		entries[aws.ToString(secret.ARN)] = secret
+	}
+
+	veto := func(arns []string, action string) []string {
+		kept := []string{}
+		for _, arn := range arns {
+			secret, ok := entries[arn]
+			if !ok {
+				secret = secretsmanagertypes.SecretListEntry{
+					ARN:  aws.String(arn),
+					Name: aws.String(secretName(arn)),
+				}
+			}
+			if rule := vetoRule(cluster, secret, action, rules); rule != "" {
+				cluster.secretArnChangeSet.vetoed = append(cluster.secretArnChangeSet.vetoed, &Veto{
+					arn:    arn,
+					action: action,
+					rule:   rule,
+				})
+				continue
+			}
+			kept = append(kept, arn)
+		}
+		return kept
+	}
+
+	cs := cluster.secretArnChangeSet
+	cs.add = veto(cs.add, _actionAssociate)
+	cs.remove = veto(cs.remove, _actionDisassociate)
+
+	sort.SliceStable(cs.vetoed, func(i, j int) bool {
+		return cs.vetoed[i].arn < cs.vetoed[j].arn
+	})
+
+	return nil
+}
+
+func vetoRule(cluster *Cluster, secret secretsmanagertypes.SecretListEntry, action string, rules []*Rule) string {
+	for _, r := range rules {
+		if r.action != _ruleActionVeto {
+			continue
+		}
+		ok, err := r.eval(cluster, secret, action)
+		if err != nil {
+			recordRuleError(cluster, r.name, secret, err)
+			return fmt.Sprintf("%v (error: %v)", r.name, err)
+		}
+		if ok {
+			return r.name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    []RuleConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			give: []RuleConfig{
+				{Name: "owner", Action: "veto", Expression: `!("Owner" in secret.tags)`},
+				{Name: "team", Action: "bind", Expression: `cluster.tags["team"] == secret.tags["team"]`},
+			},
+		}, {
+			name:    "empty_name",
+			give:    []RuleConfig{{Action: "veto", Expression: `true`}},
+			wantErr: true,
+		}, {
+			name: "duplicate_name",
+			give: []RuleConfig{
+				{Name: "owner", Action: "veto", Expression: `true`},
+				{Name: "owner", Action: "bind", Expression: `true`},
+			},
+			wantErr: true,
+		}, {
+			name:    "invalid_action",
+			give:    []RuleConfig{{Name: "owner", Action: "deny", Expression: `true`}},
+			wantErr: true,
+		}, {
+			name:    "syntax",
+			give:    []RuleConfig{{Name: "owner", Action: "veto", Expression: `secret.tags[`}},
+			wantErr: true,
+		}, {
+			name:    "not_bool",
+			give:    []RuleConfig{{Name: "owner", Action: "veto", Expression: `action + "x"`}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRules(tt.give)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestRuleMapper(t *testing.T) {
+	rules, err := newRules([]RuleConfig{
+		{Name: "team", Action: "bind", Expression: `"team" in secret.tags && cluster.tags["team"] == secret.tags["team"]`},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("payments"),
+			Tags:        map[string]string{"team": "payments"},
+		},
+	}
+
+	rule, ok := ruleMapper{rules: rules}.Map(cluster, secretsmanagertypes.SecretListEntry{
+		Name: aws.String("AmazonMSK_alice"),
+		Tags: []secretsmanagertypes.Tag{{Key: aws.String("team"), Value: aws.String("payments")}},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "rule team", rule)
+
+	_, ok = ruleMapper{rules: rules}.Map(cluster, secretsmanagertypes.SecretListEntry{
+		Name: aws.String("AmazonMSK_bob"),
+	})
+	assert.False(t, ok)
+}
+
+func TestVetoClusterSecrets(t *testing.T) {
+	rules, err := newRules([]RuleConfig{
+		{Name: "prod-owner", Action: "veto", Expression: `cluster.tags["env"] == "prod" && action == "associate" && !("Owner" in secret.tags)`},
+		{Name: "no-dev", Action: "veto", Expression: `"Env" in secret.tags && secret.tags["Env"] == "dev"`},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	secrets := []secretsmanagertypes.SecretListEntry{
+		{
+			ARN:  aws.String("alice"),
+			Name: aws.String("AmazonMSK_alice"),
+			Tags: []secretsmanagertypes.Tag{{Key: aws.String("Owner"), Value: aws.String("payments")}},
+		}, {
+			ARN:  aws.String("bob"),
+			Name: aws.String("AmazonMSK_bob"),
+		}, {
+			ARN:  aws.String("carol"),
+			Name: aws.String("AmazonMSK_carol"),
+			Tags: []secretsmanagertypes.Tag{
+				{Key: aws.String("Owner"), Value: aws.String("payments")},
+				{Key: aws.String("Env"), Value: aws.String("dev")},
+			},
+		}, {
+			ARN:  aws.String("dave"),
+			Name: aws.String("AmazonMSK_dave"),
+		},
+	}
+
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("payments"),
+			Tags:        map[string]string{"env": "prod"},
+		},
+		secretArnChangeSet: &SecretChangeSet{
+			add:    []string{"alice", "bob", "carol"},
+			remove: []string{"dave"},
+		},
+	}
+
+	vetoClusterSecrets(cluster, secrets, rules)
+
+	td.Cmp(t, cluster.secretArnChangeSet, &SecretChangeSet{
+		add:    []string{"alice"},
+		remove: []string{"dave"},
+		vetoed: []*Veto{
+			{arn: "bob", action: "associate", rule: "prod-owner"},
+			{arn: "carol", action: "associate", rule: "no-dev"},
+		},
+	})
+}
+
+func TestRuleErrors(t *testing.T) {
+	rules, err := newRules([]RuleConfig{
+		{Name: "team", Action: "bind", Expression: `secret.tags.team == cluster.tags.team`},
+		{Name: "guarded", Action: "bind", Expression: `has(secret.tags.owner) && secret.tags.owner == "payments"`},
+		{Name: "owner", Action: "veto", Expression: `secret.tags.owner != "payments"`},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	secrets := []secretsmanagertypes.SecretListEntry{
+		{
+			ARN:  aws.String("alice"),
+			Name: aws.String("AmazonMSK_alice"),
+			Tags: []secretsmanagertypes.Tag{{Key: aws.String("owner"), Value: aws.String("payments")}},
+		}, {
+			ARN:  aws.String("bob"),
+			Name: aws.String("AmazonMSK_bob"),
+		},
+	}
+
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("payments"),
+			Tags:        map[string]string{"team": "payments"},
+		},
+		secretArnChangeSet: &SecretChangeSet{remove: []string{"bob"}},
+	}
+
+	mapSecretsToClusters(cluster, secrets, []Mapper{ruleMapper{rules: rules}})
+	td.Cmp(t, cluster.bindings, map[string]string{"alice": "rule guarded"})
+
+	vetoClusterSecrets(cluster, secrets, rules)
+	td.Cmp(t, cluster.secretArnChangeSet.remove, []string{})
+	td.Cmp(t, cluster.secretArnChangeSet.vetoed, []*Veto{
+		{arn: "bob", action: "disassociate", rule: "owner (error: no such key: owner)"},
+	})
+
+	td.Cmp(t, cluster.ruleErrors, map[string]*RuleError{
+		"team":  {rule: "team", secrets: []string{"AmazonMSK_alice", "AmazonMSK_bob"}, err: "no such key: team"},
+		"owner": {rule: "owner", secrets: []string{"AmazonMSK_bob"}, err: "no such key: owner"},
+	})
+}
@@ -0,0 +1,346 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	_windowTagKey = "msk-secret-binder:window"
+	_freezeTagKey = "msk-secret-binder:freeze"
+)
+
+var _weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+type Window struct {
+	days     [7]bool
+	start    int
+	end      int
+	location *time.Location
+}
+
+type Freeze struct {
+	start time.Time
+	end   time.Time
+}
+
+type ClusterWindow struct {
+	pattern string
+	windows []*Window
+}
+
+type clusterWindowSlice []*ClusterWindow
+
+func (c *clusterWindowSlice) String() string {
+	patterns := []string{}
+	for _, cw := range *c {
+		patterns = append(patterns, cw.pattern)
+	}
+	return strings.Join(patterns, ",")
+}
+
+func (c *clusterWindowSlice) Set(value string) error {
+	pattern, spec, ok := strings.Cut(value, "=")
+	if !ok || pattern == "" {
+		return fmt.Errorf("invalid window %q: expected pattern=window", value)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid window %q: %w", value, err)
+	}
+
+	windows, err := parseWindows(spec)
+	if err != nil {
+		return err
+	}
+
+	*c = append(*c, &ClusterWindow{
+		pattern: pattern,
+		windows: windows,
+	})
+
+	return nil
+}
+
+type freezeSlice []*Freeze
+
+func (f *freezeSlice) String() string {
+	freezes := []string{}
+	for _, freeze := range *f {
+		freezes = append(freezes, freeze.String())
+	}
+	return strings.Join(freezes, ",")
+}
+
+func (f *freezeSlice) Set(value string) error {
+	freezes, err := parseFreezes(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, freezes...)
+	return nil
+}
+
+// parseWindows parses space separated windows such as
+// "Mon-Fri 22:00-04:00 Australia/Sydney Sat+Sun 00:00-24:00". Tag values may
+// not contain semicolons or commas, so a window ends after its time range or
+// its optional location, and days are joined with "+". Windows that end before
+// they start cross midnight. The location defaults to UTC.
+func parseWindows(value string) ([]*Window, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid window %q: expected days start-end [location]", value)
+	}
+
+	windows := []*Window{}
+	for len(fields) > 0 {
+		n := 2
+		if len(fields) > 2 && !isDays(fields[2]) {
+			n = 3
+		}
+		if n > len(fields) {
+			n = len(fields)
+		}
+
+		spec := strings.Join(fields[:n], " ")
+		window, err := parseWindow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", spec, err)
+		}
+		windows = append(windows, window)
+		fields = fields[n:]
+	}
+
+	return windows, nil
+}
+
+func parseWindow(spec string) (*Window, error) {
+	fields := strings.Fields(spec)
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, fmt.Errorf("expected days start-end [location]")
+	}
+
+	window := &Window{
+		location: time.UTC,
+	}
+
+	if err := parseDays(fields[0], &window.days); err != nil {
+		return nil, err
+	}
+
+	start, end, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return nil, fmt.Errorf("expected time range start-end")
+	}
+
+	var err error
+	if window.start, err = parseTimeOfDay(start); err != nil {
+		return nil, err
+	}
+	if window.end, err = parseTimeOfDay(end); err != nil {
+		return nil, err
+	}
+
+	if len(fields) == 3 {
+		if window.location, err = time.LoadLocation(fields[2]); err != nil {
+			return nil, fmt.Errorf("unknown location: %v", fields[2])
+		}
+	}
+
+	return window, nil
+}
+
+func isDays(value string) bool {
+	var days [7]bool
+	return parseDays(value, &days) == nil
+}
+
+func parseDays(value string, days *[7]bool) error {
+	if value == "*" || strings.EqualFold(value, "daily") {
+		for i := range days {
+			days[i] = true
+		}
+		return nil
+	}
+
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '+' || r == ','
+	})
+	if len(parts) == 0 {
+		return fmt.Errorf("unknown day: %v", value)
+	}
+
+	for _, part := range parts {
+		first, last, isRange := strings.Cut(strings.ToLower(part), "-")
+
+		from, ok := _weekdays[first]
+		if !ok {
+			return fmt.Errorf("unknown day: %v", first)
+		}
+		to := from
+		if isRange {
+			if to, ok = _weekdays[last]; !ok {
+				return fmt.Errorf("unknown day: %v", last)
+			}
+		}
+
+		for d := from; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseTimeOfDay(value string) (int, error) {
+	hh, mm, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time: %v", value)
+	}
+
+	h, err := strconv.Atoi(hh)
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid time: %v", value)
+	}
+	m, err := strconv.Atoi(mm)
+	if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time: %v", value)
+	}
+
+	return h*60 + m, nil
+}
+
+func (w *Window) Contains(t time.Time) bool {
+	t = t.In(w.location)
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if w.start <= w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+
+	yesterday := (day + 6) % 7
+
+	return (w.days[day] && minute >= w.start) || (w.days[yesterday] && minute < w.end)
+}
+
+// parseFreezes parses space separated freezes such as
+// "2026-12-20T00:00:00Z/2027-01-05T00:00:00Z".
+func parseFreezes(value string) ([]*Freeze, error) {
+	specs := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || unicode.IsSpace(r)
+	})
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("invalid freeze %q: expected start/end", value)
+	}
+
+	freezes := []*Freeze{}
+	for _, spec := range specs {
+		freeze, err := parseFreeze(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid freeze %q: %w", spec, err)
+		}
+		freezes = append(freezes, freeze)
+	}
+
+	return freezes, nil
+}
+
+func parseFreeze(spec string) (*Freeze, error) {
+	start, end, ok := strings.Cut(spec, "/")
+	if !ok {
+		return nil, fmt.Errorf("expected start/end")
+	}
+
+	freeze := &Freeze{}
+
+	var err error
+	if freeze.start, err = time.Parse(time.RFC3339, start); err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	if freeze.end, err = time.Parse(time.RFC3339, end); err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+	if !freeze.end.After(freeze.start) {
+		return nil, fmt.Errorf("end is not after start")
+	}
+
+	return freeze, nil
+}
+
+func (f *Freeze) Contains(t time.Time) bool {
+	return !t.Before(f.start) && t.Before(f.end)
+}
+
+func (f *Freeze) String() string {
+	return fmt.Sprintf("%v/%v", f.start.Format(time.RFC3339), f.end.Format(time.RFC3339))
+}
+
+func scheduleCluster(cluster *Cluster, windows []*ClusterWindow, freezes []*Freeze, now time.Time) error {
+	tags := cluster.clusterInfo.Tags
+	name := aws.ToString(cluster.clusterInfo.ClusterName)
+
+	if value, ok := tags[_freezeTagKey]; ok {
+		tagFreezes, err := parseFreezes(value)
+		if err != nil {
+			cluster.deferred = "invalid freeze tag"
+			return fmt.Errorf("unable to parse freeze tag for %v: %w", clusterLabel(cluster), err)
+		}
+		freezes = append(append([]*Freeze{}, freezes...), tagFreezes...)
+	}
+
+	for _, freeze := range freezes {
+		if freeze.Contains(now) {
+			cluster.deferred = fmt.Sprintf("freeze until %v", freeze.end.Format(time.RFC3339))
+			return nil
+		}
+	}
+
+	var clusterWindows []*Window
+	if value, ok := tags[_windowTagKey]; ok {
+		tagWindows, err := parseWindows(value)
+		if err != nil {
+			cluster.deferred = "invalid window tag"
+			return fmt.Errorf("unable to parse window tag for %v: %w", clusterLabel(cluster), err)
+		}
+		clusterWindows = tagWindows
+	} else {
+		for _, cw := range windows {
+			if ok, _ := path.Match(cw.pattern, name); ok {
+				clusterWindows = cw.windows
+				break
+			}
+		}
+	}
+
+	if len(clusterWindows) == 0 {
+		return nil
+	}
+
+	for _, window := range clusterWindows {
+		if window.Contains(now) {
+			return nil
+		}
+	}
+	cluster.deferred = "outside window"
+
+	return nil
+}
@@ -0,0 +1,334 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWindow(t *testing.T) {
+	weekdays := [7]bool{false, true, true, true, true, true, false}
+
+	tests := []struct {
+		name string
+		give string
+		want *Window
+		err  string
+	}{
+		{
+			name: "range",
+			give: "Mon-Fri 09:00-17:00",
+			want: &Window{days: weekdays, start: 540, end: 1020, location: time.UTC},
+		}, {
+			name: "list",
+			give: "Sat+Sun 00:00-24:00",
+			want: &Window{days: [7]bool{true, false, false, false, false, false, true}, start: 0, end: 1440, location: time.UTC},
+		}, {
+			name: "wrapping_range",
+			give: "Fri-Mon 22:00-04:00",
+			want: &Window{days: [7]bool{true, true, false, false, false, true, true}, start: 1320, end: 240, location: time.UTC},
+		}, {
+			name: "every_day",
+			give: "Daily 01:30-02:30",
+			want: &Window{days: [7]bool{true, true, true, true, true, true, true}, start: 90, end: 150, location: time.UTC},
+		}, {
+			name: "unknown_day",
+			give: "Mon-Fry 09:00-17:00",
+			err:  "unknown day: fry",
+		}, {
+			name: "invalid_time",
+			give: "Mon 9-17",
+			err:  "invalid time: 9",
+		}, {
+			name: "unknown_location",
+			give: "Mon 09:00-17:00 Nowhere/City",
+			err:  "unknown location: Nowhere/City",
+		}, {
+			name: "missing_time",
+			give: "Mon",
+			err:  "expected days start-end [location]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWindow(tt.give)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseWindows(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want []string
+		err  string
+	}{
+		{
+			name: "single",
+			give: "Mon-Fri 22:00-04:00 Australia/Sydney",
+			want: []string{"Mon-Fri 22:00-04:00 Australia/Sydney"},
+		}, {
+			name: "multiple",
+			give: "Mon-Fri 22:00-04:00 Australia/Sydney Sat+Sun 00:00-24:00",
+			want: []string{"Mon-Fri 22:00-04:00 Australia/Sydney", "Sat+Sun 00:00-24:00"},
+		}, {
+			name: "multiple_without_location",
+			give: "Tue+Thu 01:00-02:00 Daily 12:00-12:30 UTC",
+			want: []string{"Tue+Thu 01:00-02:00", "Daily 12:00-12:30 UTC"},
+		}, {
+			name: "semicolons",
+			give: "Mon 01:00-02:00; Tue 01:00-02:00",
+			want: []string{"Mon 01:00-02:00", "Tue 01:00-02:00"},
+		}, {
+			name: "trailing_days",
+			give: "Mon 01:00-02:00 Tue",
+			err:  `invalid window "Tue": expected days start-end [location]`,
+		}, {
+			name: "empty",
+			give: "",
+			err:  `invalid window "": expected days start-end [location]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWindows(tt.give)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+
+			want := []*Window{}
+			for _, spec := range tt.want {
+				window, err := parseWindow(spec)
+				assert.NoError(t, err)
+				want = append(want, window)
+			}
+			td.Cmp(t, got, want)
+		})
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		time string
+		want bool
+	}{
+		{
+			name: "inside",
+			give: "Mon-Fri 09:00-17:00",
+			time: "2026-10-19T10:00:00Z",
+			want: true,
+		}, {
+			name: "before_start",
+			give: "Mon-Fri 09:00-17:00",
+			time: "2026-10-19T08:59:00Z",
+			want: false,
+		}, {
+			name: "at_end",
+			give: "Mon-Fri 09:00-17:00",
+			time: "2026-10-19T17:00:00Z",
+			want: false,
+		}, {
+			name: "wrong_day",
+			give: "Mon-Fri 09:00-17:00",
+			time: "2026-10-18T10:00:00Z",
+			want: false,
+		}, {
+			name: "overnight_same_day",
+			give: "Mon 22:00-04:00",
+			time: "2026-10-19T23:00:00Z",
+			want: true,
+		}, {
+			name: "overnight_next_day",
+			give: "Mon 22:00-04:00",
+			time: "2026-10-20T03:00:00Z",
+			want: true,
+		}, {
+			name: "overnight_previous_day",
+			give: "Mon 22:00-04:00",
+			time: "2026-10-19T03:00:00Z",
+			want: false,
+		}, {
+			name: "location",
+			give: "Tue 09:00-17:00 Australia/Sydney",
+			time: "2026-10-19T23:00:00Z",
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := parseWindow(tt.give)
+			assert.NoError(t, err)
+
+			now, err := time.Parse(time.RFC3339, tt.time)
+			assert.NoError(t, err)
+
+			td.Cmp(t, window.Contains(now), tt.want)
+		})
+	}
+}
+
+func TestParseFreeze(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		err  string
+	}{
+		{
+			name: "valid",
+			give: "2026-12-20T00:00:00Z/2027-01-05T00:00:00Z",
+		}, {
+			name: "missing_end",
+			give: "2026-12-20T00:00:00Z",
+			err:  "expected start/end",
+		}, {
+			name: "reversed",
+			give: "2027-01-05T00:00:00Z/2026-12-20T00:00:00Z",
+			err:  "end is not after start",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFreeze(tt.give)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			td.Cmp(t, got.String(), tt.give)
+		})
+	}
+}
+
+func TestParseFreezes(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want []string
+		err  string
+	}{
+		{
+			name: "single",
+			give: "2026-12-20T00:00:00Z/2027-01-05T00:00:00Z",
+			want: []string{"2026-12-20T00:00:00Z/2027-01-05T00:00:00Z"},
+		}, {
+			name: "multiple",
+			give: "2026-12-20T00:00:00+11:00/2027-01-05T00:00:00+11:00 2027-04-01T00:00:00Z/2027-04-02T00:00:00Z",
+			want: []string{"2026-12-20T00:00:00+11:00/2027-01-05T00:00:00+11:00", "2027-04-01T00:00:00Z/2027-04-02T00:00:00Z"},
+		}, {
+			name: "empty",
+			give: " ",
+			err:  `invalid freeze " ": expected start/end`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFreezes(tt.give)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+
+			specs := []string{}
+			for _, freeze := range got {
+				specs = append(specs, freeze.String())
+			}
+			td.Cmp(t, specs, tt.want)
+		})
+	}
+}
+
+func TestScheduleCluster(t *testing.T) {
+	now := time.Date(2026, 12, 22, 10, 0, 0, 0, time.UTC)
+
+	windows := []*ClusterWindow{}
+	for _, v := range []string{"*-prd=Sat 00:00-24:00", "*-stg=daily 09:00-17:00"} {
+		assert.NoError(t, (*clusterWindowSlice)(&windows).Set(v))
+	}
+
+	freezes, err := parseFreezes("2026-12-24T00:00:00Z/2027-01-02T00:00:00Z")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		giveName    string
+		giveTags    map[string]string
+		giveFreezes []*Freeze
+		want        string
+		err         bool
+	}{
+		{
+			name:     "no_window",
+			giveName: "payments-dev",
+			want:     "",
+		}, {
+			name:     "window_open",
+			giveName: "payments-stg",
+			want:     "",
+		}, {
+			name:     "window_closed",
+			giveName: "payments-prd",
+			want:     "outside window",
+		}, {
+			name:     "tag_overrides_window",
+			giveName: "payments-prd",
+			giveTags: map[string]string{"msk-secret-binder:window": "Sat+Sun 00:00-24:00 Tue 08:00-12:00 UTC"},
+			want:     "",
+		}, {
+			name:     "tag_freeze",
+			giveName: "payments-dev",
+			giveTags: map[string]string{"msk-secret-binder:freeze": "2026-11-01T00:00:00Z/2026-11-02T00:00:00Z 2026-12-21T00:00:00Z/2026-12-23T00:00:00Z"},
+			want:     "freeze until 2026-12-23T00:00:00Z",
+		}, {
+			name:        "global_freeze",
+			giveName:    "payments-stg",
+			giveFreezes: []*Freeze{{start: now.Add(-time.Hour), end: now.Add(time.Hour)}},
+			want:        "freeze until 2026-12-22T11:00:00Z",
+		}, {
+			name:        "future_freeze",
+			giveName:    "payments-stg",
+			giveFreezes: freezes,
+			want:        "",
+		}, {
+			name:     "invalid_tag",
+			giveName: "payments-dev",
+			giveTags: map[string]string{"msk-secret-binder:window": "Someday"},
+			want:     "invalid window tag",
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &Cluster{
+				clusterInfo: &kafkatypes.ClusterInfo{
+					ClusterName: aws.String(tt.giveName),
+					Tags:        tt.giveTags,
+				},
+			}
+
+			err := scheduleCluster(cluster, windows, tt.giveFreezes, now)
+			td.Cmp(t, err != nil, tt.err)
+			td.Cmp(t, cluster.deferred, tt.want)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 	"github.com/aws/smithy-go"
@@ -12,18 +13,20 @@ import (
 
 type SecretsManagerClientAPI interface {
 	ListSecrets(context.Context, *secretsmanager.ListSecretsInput, ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error)
+	TagResource(context.Context, *secretsmanager.TagResourceInput, ...func(*secretsmanager.Options)) (*secretsmanager.TagResourceOutput, error)
+	UntagResource(context.Context, *secretsmanager.UntagResourceInput, ...func(*secretsmanager.Options)) (*secretsmanager.UntagResourceOutput, error)
 }
 
 const (
 	_filterValue = "AmazonMSK_"
 )
 
-func listSecrets(cl SecretsManagerClientAPI) (secrets []types.SecretListEntry, err error) {
+func listSecrets(cl SecretsManagerClientAPI, filterValue string, pageSize int32) (secrets []types.SecretListEntry, err error) {
 	secrets = []types.SecretListEntry{}
 
 	filter := types.Filter{
 		Key:    types.FilterNameStringTypeName,
-		Values: []string{_filterValue},
+		Values: []string{filterValue},
 	}
 
 	input := &secretsmanager.ListSecretsInput{
@@ -31,7 +34,7 @@ func listSecrets(cl SecretsManagerClientAPI) (secrets []types.SecretListEntry, e
 	}
 
 	options := func(o *secretsmanager.ListSecretsPaginatorOptions) {
-		o.Limit = 100
+		o.Limit = pageSize
 	}
 
 	pagination := secretsmanager.NewListSecretsPaginator(cl, input, options)
@@ -49,3 +52,36 @@ func listSecrets(cl SecretsManagerClientAPI) (secrets []types.SecretListEntry, e
 
 	return secrets, nil
 }
+
+func tagOwnedSecrets(cl SecretsManagerClientAPI, clusterArn *string, secretArnList []string) error {
+	for _, arn := range secretArnList {
+		_, err := cl.TagResource(context.TODO(), &secretsmanager.TagResourceInput{
+			SecretId: aws.String(arn),
+			Tags: []types.Tag{
+				{
+					Key:   aws.String(ownershipTagKey(clusterArn)),
+					Value: aws.String(_ownershipTagValue),
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("unable to tag secret %v: %w", arn, err)
+		}
+	}
+
+	return nil
+}
+
+func untagOwnedSecrets(cl SecretsManagerClientAPI, clusterArn *string, secretArnList []string) error {
+	for _, arn := range secretArnList {
+		_, err := cl.UntagResource(context.TODO(), &secretsmanager.UntagResourceInput{
+			SecretId: aws.String(arn),
+			TagKeys:  []string{ownershipTagKey(clusterArn)},
+		})
+		if err != nil {
+			return fmt.Errorf("unable to untag secret %v: %w", arn, err)
+		}
+	}
+
+	return nil
+}
@@ -16,6 +16,8 @@ import (
 
 type mockSecretsManagerClientAPI struct {
 	listSecretsOutput []*secretsmanager.ListSecretsOutput
+	tagged            map[string][]string
+	untagged          map[string][]string
 	err               error
 }
 
@@ -39,6 +41,20 @@ func (m mockSecretsManagerClientAPI) ListSecrets(ctx context.Context, input *sec
 	}, m.err
 }
 
+func (m mockSecretsManagerClientAPI) TagResource(ctx context.Context, input *secretsmanager.TagResourceInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.TagResourceOutput, error) {
+	for _, tag := range input.Tags {
+		m.tagged[aws.ToString(input.SecretId)] = append(m.tagged[aws.ToString(input.SecretId)], aws.ToString(tag.Key))
+	}
+
+	return &secretsmanager.TagResourceOutput{}, m.err
+}
+
+func (m mockSecretsManagerClientAPI) UntagResource(ctx context.Context, input *secretsmanager.UntagResourceInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.UntagResourceOutput, error) {
+	m.untagged[aws.ToString(input.SecretId)] = append(m.untagged[aws.ToString(input.SecretId)], input.TagKeys...)
+
+	return &secretsmanager.UntagResourceOutput{}, m.err
+}
+
 func TestListSecrets(t *testing.T) {
 	tests := []struct {
 		name string
@@ -108,9 +124,73 @@ func TestListSecrets(t *testing.T) {
 				err:               tt.err,
 			}
 
-			got, err := listSecrets(cl)
+			got, err := listSecrets(cl, "AmazonMSK_", 100)
 			assert.ErrorIs(t, err, tt.err)
 			td.Cmp(t, got, tt.want)
 		})
 	}
 }
+
+func TestTagOwnedSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		give []string
+		want map[string][]string
+		err  error
+	}{
+		{
+			name: "many",
+			give: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_example-123456",
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_example-234567",
+			},
+			want: map[string][]string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_example-123456": {"msk-secret-binder:cluster:a1b2c3d4-1"},
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_example-234567": {"msk-secret-binder:cluster:a1b2c3d4-1"},
+			},
+		}, {
+			name: "none",
+			give: []string{},
+			want: map[string][]string{},
+		}, {
+			name: "error",
+			give: []string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_example-123456",
+			},
+			want: map[string][]string{
+				"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_example-123456": {"msk-secret-binder:cluster:a1b2c3d4-1"},
+			},
+			err: errors.New("access denied"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := &mockSecretsManagerClientAPI{
+				tagged: map[string][]string{},
+				err:    tt.err,
+			}
+
+			err := tagOwnedSecrets(cl, aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/a1b2c3d4-1"), tt.give)
+			assert.ErrorIs(t, err, tt.err)
+			td.Cmp(t, cl.tagged, tt.want)
+		})
+	}
+}
+
+func TestUntagOwnedSecrets(t *testing.T) {
+	cl := &mockSecretsManagerClientAPI{
+		untagged: map[string][]string{},
+	}
+
+	give := []string{
+		"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_example-123456",
+	}
+	want := map[string][]string{
+		"arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_example-123456": {"msk-secret-binder:cluster:a1b2c3d4-1"},
+	}
+
+	err := untagOwnedSecrets(cl, aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/a1b2c3d4-1"), give)
+	assert.NoError(t, err)
+	td.Cmp(t, cl.untagged, want)
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+const (
+	_clusterSelectorTagKey = "ClusterSelector"
+)
+
+var _selectorEquals = regexp.MustCompile(`\s*=\s*`)
+
+// Selector matches clusters by their tags. A requirement without a value only
+// requires the tag to be present.
+type Selector []Requirement
+
+type Requirement struct {
+	key    string
+	value  string
+	exists bool
+}
+
+// parseSelector parses a selector such as env=prod,team=payments. Requirements
+// may also be separated by spaces as commas are not allowed in tag values.
+func parseSelector(value string) (Selector, error) {
+	items := strings.FieldsFunc(_selectorEquals.ReplaceAllString(value, "="), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	selector := Selector{}
+	for _, item := range items {
+		key, v, ok := strings.Cut(item, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid selector %q: empty key", value)
+		}
+
+		selector = append(selector, Requirement{
+			key:    key,
+			value:  v,
+			exists: !ok,
+		})
+	}
+
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("invalid selector %q: no requirements", value)
+	}
+
+	return selector, nil
+}
+
+func (s Selector) Matches(tags map[string]string) bool {
+	for _, r := range s {
+		v, ok := tags[r.key]
+		if !ok || (!r.exists && v != r.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isSelectedCluster(cluster *Cluster, tags []secretsmanagertypes.Tag) bool {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) != _clusterSelectorTagKey {
+			continue
+		}
+		selector, err := parseSelector(aws.ToString(tag.Value))
+		if err != nil {
+			continue
+		}
+		if selector.Matches(cluster.clusterInfo.Tags) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    string
+		want    Selector
+		wantErr bool
+	}{
+		{
+			name: "single",
+			give: "env=prod",
+			want: Selector{{key: "env", value: "prod"}},
+		}, {
+			name: "multiple",
+			give: "env=prod, team=payments",
+			want: Selector{{key: "env", value: "prod"}, {key: "team", value: "payments"}},
+		}, {
+			name: "spaces",
+			give: "env=prod team=payments",
+			want: Selector{{key: "env", value: "prod"}, {key: "team", value: "payments"}},
+		}, {
+			name: "spaces_around_equals",
+			give: "env = prod  team= payments",
+			want: Selector{{key: "env", value: "prod"}, {key: "team", value: "payments"}},
+		}, {
+			name: "mixed",
+			give: "env=prod, team=payments pci",
+			want: Selector{{key: "env", value: "prod"}, {key: "team", value: "payments"}, {key: "pci", exists: true}},
+		}, {
+			name: "exists",
+			give: "pci",
+			want: Selector{{key: "pci", exists: true}},
+		}, {
+			name:    "empty",
+			give:    " , ",
+			wantErr: true,
+		}, {
+			name:    "empty_key",
+			give:    "=prod",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSelector(tt.give)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestIsSelectedCluster(t *testing.T) {
+	cluster := &Cluster{
+		clusterInfo: &kafkatypes.ClusterInfo{
+			ClusterName: aws.String("payments-2"),
+			Tags:        map[string]string{"env": "prod", "team": "payments", "pci": ""},
+		},
+	}
+
+	tests := []struct {
+		name string
+		give string
+		want bool
+	}{
+		{name: "match", give: "env=prod,team=payments", want: true},
+		{name: "exists", give: "pci", want: true},
+		{name: "value_mismatch", give: "env=prod,team=orders", want: false},
+		{name: "missing_tag", give: "region=eu", want: false},
+		{name: "invalid", give: "=prod", want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tags := []secretsmanagertypes.Tag{
+				{Key: aws.String(_clusterSelectorTagKey), Value: aws.String(tt.give)},
+			}
+			assert.Equal(t, tt.want, isSelectedCluster(cluster, tags))
+			assert.Equal(t, tt.want, isClusterSecret(cluster, tags))
+		})
+	}
+}
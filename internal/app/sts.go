@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+type STSClientAPI interface {
+	GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+func newSTSClient(cfg aws.Config, endpoints Endpoints) *sts.Client {
+	return sts.NewFromConfig(cfg, func(o *sts.Options) {
+		if endpoints.sts != "" {
+			o.EndpointResolver = sts.EndpointResolverFromURL(endpoints.sts)
+		}
+	})
+}
+
+// assumeRole returns a copy of the config with credentials for the role. The
+// credentials are cached and refreshed before they expire.
+func assumeRole(cfg aws.Config, opts AWSOptions, roleArn string, tags ...ststypes.Tag) aws.Config {
+	provider := stscreds.NewAssumeRoleProvider(newSTSClient(cfg, opts.endpoints), roleArn, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = opts.sessionName
+		if opts.externalID != "" {
+			o.ExternalID = aws.String(opts.externalID)
+		}
+		o.Tags = tags
+	})
+
+	roleCfg := cfg.Copy()
+	roleCfg.Credentials = aws.NewCredentialsCache(provider)
+
+	return roleCfg
+}
+
+func getCallerIdentity(cl STSClientAPI) (arn string, err error) {
+	output, err := cl.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return "", fmt.Errorf("unable to get caller identity: %v", apiErr.ErrorMessage())
+		}
+		return "", fmt.Errorf("unable to get caller identity: %w", err)
+	}
+
+	return aws.ToString(output.Arn), nil
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(path, []byte("[profile binder]\nregion = ap-southeast-2\n"), 0o600))
+	t.Setenv("AWS_CONFIG_FILE", path)
+	t.Setenv("AWS_REGION", "")
+
+	cfg, err := loadConfig(AWSOptions{region: "eu-west-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+
+	cfg, err = loadConfig(AWSOptions{region: "eu-west-1", roleArn: "arn:aws:iam::111111111111:role/binder"})
+	assert.Nil(t, err)
+	assert.IsType(t, &aws.CredentialsCache{}, cfg.Credentials)
+
+	cfg, err = loadConfig(AWSOptions{profile: "binder"})
+	assert.Nil(t, err)
+	assert.Equal(t, "ap-southeast-2", cfg.Region)
+}
+
+func TestLoadConfigEndpoints(t *testing.T) {
+	var mu sync.Mutex
+	requests := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var request string
+		switch {
+		case r.PostForm.Get("Action") == "GetCallerIdentity":
+			request = "sts GetCallerIdentity"
+			w.Header().Set("Content-Type", "text/xml")
+			fmt.Fprint(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult><Arn>arn:aws:iam::111111111111:user/alice</Arn></GetCallerIdentityResult>
+</GetCallerIdentityResponse>`)
+		case r.PostForm.Get("Action") == "AssumeRole":
+			request = "sts AssumeRole"
+			w.Header().Set("Content-Type", "text/xml")
+			fmt.Fprint(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>AKIDREADER</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token</SessionToken>
+      <Expiration>2030-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`)
+		case r.Header.Get("X-Amz-Target") != "":
+			request = "secretsmanager " + r.Header.Get("X-Amz-Target")
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			fmt.Fprint(w, `{"SecretList":[]}`)
+		default:
+			request = "kafka " + r.Method + " " + r.URL.Path
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"clusterInfoList":[]}`)
+		}
+
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(path, []byte(""), 0o600))
+	t.Setenv("AWS_CONFIG_FILE", path)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDBASE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	opts := AWSOptions{
+		region:      "us-east-1",
+		roleArn:     "arn:aws:iam::111111111111:role/reader",
+		sessionName: _sessionName,
+		endpoints:   Endpoints{kafka: server.URL, secretsmanager: server.URL, sts: server.URL},
+	}
+
+	cfg, err := loadConfig(opts)
+	if !assert.Nil(t, err) {
+		return
+	}
+	caller, err := getCallerIdentity(newSTSClient(cfg, opts.endpoints))
+	assert.Nil(t, err)
+	td.Cmp(t, caller, "arn:aws:iam::111111111111:user/alice")
+
+	svc := newService(cfg, opts.endpoints)
+	_, err = svc.kafka.ListClusters(context.TODO(), &kafka.ListClustersInput{})
+	assert.Nil(t, err)
+	_, err = svc.secretsmanager.ListSecrets(context.TODO(), &secretsmanager.ListSecretsInput{})
+	assert.Nil(t, err)
+
+	td.Cmp(t, requests, []string{
+		"sts AssumeRole",
+		"sts GetCallerIdentity",
+		"kafka GET /v1/clusters",
+		"secretsmanager secretsmanager.ListSecrets",
+	})
+}
@@ -4,38 +4,134 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/service/kafka"
 	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
-	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 )
 
+type Fleet struct {
+	services   []*Service
+	journal    *Journal
+	checkpoint *Checkpoint
+	locker     Locker
+	heartbeat  *LockHeartbeat
+}
+
 type Service struct {
-	kafka          *kafka.Client
-	secretsmanager *secretsmanager.Client
-	clusters       []*Cluster
-	secrets        []secretsmanagertypes.SecretListEntry
+	account              string
+	region               string
+	kafka                KafkaClientAPI
+	secretsmanager       SecretsManagerClientAPI
+	kafkaWriter          KafkaClientAPI
+	secretsmanagerWriter SecretsManagerClientAPI
+	pageSize             int32
+	clusters             []*Cluster
+	secrets              []secretsmanagertypes.SecretListEntry
 }
 
 type Cluster struct {
 	clusterInfo              *kafkatypes.ClusterInfo
+	service                  *Service
 	assosciatedSecretArnList []string
 	secretArnList            []string
+	ignoredSecretArnList     []string
+	ownedSecretArnList       []string
 	secretArnChangeSet       *SecretChangeSet
+	quota                    *Quota
+	ignored                  bool
+	removals                 bool
+	adopt                    bool
+	deferred                 string
+	tagKey                   string
+	concurrencyGroup         string
+	concurrency              int
+	protected                []string
+	bindings                 map[string]string
+	ruleErrors               map[string]*RuleError
+}
+
+func (s *Service) location() string {
+	if s.account == "" {
+		return s.region
+	}
+
+	return fmt.Sprintf("%v/%v", s.account, s.region)
+}
+
+// writeKafka returns the client used for changes, falling back to the read
+// client when no separate write credentials are configured.
+func (s *Service) writeKafka() KafkaClientAPI {
+	if s.kafkaWriter == nil {
+		return s.kafka
+	}
+
+	return s.kafkaWriter
+}
+
+func (s *Service) writeSecretsManager() SecretsManagerClientAPI {
+	if s.secretsmanagerWriter == nil {
+		return s.secretsmanager
+	}
+
+	return s.secretsmanagerWriter
+}
+
+func (f *Fleet) multiAccount() bool {
+	for _, svc := range f.services {
+		if svc.account != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Fleet) clusters() []*Cluster {
+	clusters := []*Cluster{}
+	for _, svc := range f.services {
+		clusters = append(clusters, svc.clusters...)
+	}
+
+	return clusters
 }
 
 type SecretChangeSet struct {
-	add    []string
-	remove []string
+	add       []string
+	remove    []string
+	unmanaged []string
+	adopt     []string
+	protected []string
+	vetoed    []*Veto
 }
 
 func (s SecretChangeSet) String() string {
+	return s.format(nil)
+}
+
+// format renders the change set, annotating additions with the rule that
+// bound the secret when known.
+func (s SecretChangeSet) format(rules map[string]string) string {
 	var str strings.Builder
 	for _, v := range s.add {
+		if rule, ok := rules[v]; ok {
+			fmt.Fprintf(&str, "+%v (%v)\n", v, rule)
+			continue
+		}
 		fmt.Fprintf(&str, "+%v\n", v)
 	}
 	for _, v := range s.remove {
 		fmt.Fprintf(&str, "-%v\n", v)
 	}
+	for _, v := range s.unmanaged {
+		fmt.Fprintf(&str, "~%v (unmanaged)\n", v)
+	}
+	for _, v := range s.adopt {
+		fmt.Fprintf(&str, "*%v (adopt)\n", v)
+	}
+	for _, v := range s.protected {
+		fmt.Fprintf(&str, "=%v (protected)\n", v)
+	}
+	for _, v := range s.vetoed {
+		fmt.Fprintf(&str, "!%v (%v vetoed by %v)\n", v.arn, v.action, v.rule)
+	}
 	return str.String()
 }
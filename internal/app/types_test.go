@@ -46,6 +46,38 @@ func TestString(t *testing.T) {
 				-peach
 				-coconut
 			`),
+		}, {
+			name: "unmanaged",
+			give: SecretChangeSet{
+				add:       []string{"apple"},
+				remove:    []string{"peach"},
+				unmanaged: []string{"lemon"},
+			},
+			want: heredoc.Docf(`
+				+apple
+				-peach
+				~lemon (unmanaged)
+			`),
+		}, {
+			name: "adopt",
+			give: SecretChangeSet{
+				add:   []string{"apple"},
+				adopt: []string{"pear"},
+			},
+			want: heredoc.Docf(`
+				+apple
+				*pear (adopt)
+			`),
+		}, {
+			name: "protected",
+			give: SecretChangeSet{
+				remove:    []string{"peach"},
+				protected: []string{"coconut"},
+			},
+			want: heredoc.Docf(`
+				-peach
+				=coconut (protected)
+			`),
 		}, {
 			name: "empty",
 			give: SecretChangeSet{},
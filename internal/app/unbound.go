@@ -0,0 +1,102 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	"github.com/mikelorant/msk-secret-binder/internal/fuzzy"
+	"github.com/mikelorant/msk-secret-binder/internal/sliceutil"
+)
+
+type UnboundSecret struct {
+	name           string
+	arn            string
+	tagValue       string
+	nearMissTags   []string
+	closestCluster string
+	selectorErr    string
+}
+
+// findUnboundSecrets matches each secret against the clusters using the tag
+// keys it carries as clusters may override the tag key.
+func findUnboundSecrets(clusters []*Cluster, secrets []secretsmanagertypes.SecretListEntry) []*UnboundSecret {
+	unbound := []*UnboundSecret{}
+
+	keys := []string{}
+	names := map[string][]string{}
+	for _, cluster := range clusters {
+		key := clusterTagKey(cluster)
+		if _, ok := names[key]; !ok {
+			keys = append(keys, key)
+		}
+		names[key] = append(names[key], aws.ToString(cluster.clusterInfo.ClusterName))
+	}
+
+	for _, secret := range secrets {
+		if isIgnoredSecret(secret.Tags) || isBoundSecret(clusters, secret) {
+			continue
+		}
+
+		us := &UnboundSecret{
+			name: aws.ToString(secret.Name),
+			arn:  aws.ToString(secret.ARN),
+		}
+
+		value := ""
+		candidates := []string{}
+		for _, tag := range secret.Tags {
+			key := aws.ToString(tag.Key)
+			if key == _clusterSelectorTagKey {
+				if _, err := parseSelector(aws.ToString(tag.Value)); err != nil {
+					us.selectorErr = err.Error()
+				}
+				continue
+			}
+			if _, ok := names[key]; ok {
+				us.tagValue = aws.ToString(tag.Value)
+				value = us.tagValue
+				candidates = names[key]
+				continue
+			}
+			for _, tagKey := range keys {
+				if !isNearMissTagKey(key, tagKey) {
+					continue
+				}
+				us.nearMissTags = append(us.nearMissTags, key)
+				if value == "" {
+					value = aws.ToString(tag.Value)
+					candidates = names[tagKey]
+				}
+				break
+			}
+		}
+
+		if value != "" {
+			us.closestCluster, _ = fuzzy.Closest(value, candidates)
+		}
+
+		unbound = append(unbound, us)
+	}
+
+	return unbound
+}
+
+func isBoundSecret(clusters []*Cluster, secret secretsmanagertypes.SecretListEntry) bool {
+	for _, cluster := range clusters {
+		if isClusterSecret(cluster, secret.Tags) || sliceutil.Contains(cluster.secretArnList, aws.ToString(secret.ARN)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNearMissTagKey(key, tagKey string) bool {
+	if key == tagKey {
+		return false
+	}
+
+	return strings.EqualFold(strings.TrimSpace(key), tagKey)
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	secretsmanagertypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestFindUnboundSecrets(t *testing.T) {
+	clusters := []*Cluster{
+		{clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("payments-prd")}},
+		{clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("orders-prd")}},
+		{clusterInfo: &kafkatypes.ClusterInfo{ClusterName: aws.String("billing-prd")}, tagKey: "Team"},
+	}
+
+	tests := []struct {
+		name string
+		give []secretsmanagertypes.SecretListEntry
+		want []*UnboundSecret
+	}{
+		{
+			name: "bound",
+			give: []secretsmanagertypes.SecretListEntry{
+				{
+					Name: aws.String("AmazonMSK_payments"),
+					ARN:  aws.String("arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments-123456"),
+					Tags: []secretsmanagertypes.Tag{
+						{Key: aws.String("Cluster"), Value: aws.String("payments")},
+					},
+				},
+			},
+			want: []*UnboundSecret{},
+		}, {
+			name: "typo",
+			give: []secretsmanagertypes.SecretListEntry{
+				{
+					Name: aws.String("AmazonMSK_payments"),
+					ARN:  aws.String("arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments-123456"),
+					Tags: []secretsmanagertypes.Tag{
+						{Key: aws.String("Cluster"), Value: aws.String("paymnets-prd")},
+					},
+				},
+			},
+			want: []*UnboundSecret{
+				{
+					name:           "AmazonMSK_payments",
+					arn:            "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_payments-123456",
+					tagValue:       "paymnets-prd",
+					closestCluster: "payments-prd",
+				},
+			},
+		}, {
+			name: "near_miss_key",
+			give: []secretsmanagertypes.SecretListEntry{
+				{
+					Name: aws.String("AmazonMSK_orders"),
+					ARN:  aws.String("arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_orders-234567"),
+					Tags: []secretsmanagertypes.Tag{
+						{Key: aws.String("cluster"), Value: aws.String("orders-prd")},
+						{Key: aws.String("Cluster "), Value: aws.String("orders-prd")},
+					},
+				},
+			},
+			want: []*UnboundSecret{
+				{
+					name:           "AmazonMSK_orders",
+					arn:            "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_orders-234567",
+					nearMissTags:   []string{"cluster", "Cluster "},
+					closestCluster: "orders-prd",
+				},
+			},
+		}, {
+			name: "cluster_tag_key",
+			give: []secretsmanagertypes.SecretListEntry{
+				{
+					Name: aws.String("AmazonMSK_billing"),
+					ARN:  aws.String("arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_billing-456789"),
+					Tags: []secretsmanagertypes.Tag{
+						{Key: aws.String("Team"), Value: aws.String("biling-prd")},
+					},
+				},
+				{
+					Name: aws.String("AmazonMSK_billing_ops"),
+					ARN:  aws.String("arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_billing_ops-567890"),
+					Tags: []secretsmanagertypes.Tag{
+						{Key: aws.String("team"), Value: aws.String("billing-prd")},
+					},
+				},
+			},
+			want: []*UnboundSecret{
+				{
+					name:           "AmazonMSK_billing",
+					arn:            "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_billing-456789",
+					tagValue:       "biling-prd",
+					closestCluster: "billing-prd",
+				},
+				{
+					name:           "AmazonMSK_billing_ops",
+					arn:            "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_billing_ops-567890",
+					nearMissTags:   []string{"team"},
+					closestCluster: "billing-prd",
+				},
+			},
+		}, {
+			name: "malformed_selector",
+			give: []secretsmanagertypes.SecretListEntry{
+				{
+					Name: aws.String("AmazonMSK_shared"),
+					ARN:  aws.String("arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_shared-678901"),
+					Tags: []secretsmanagertypes.Tag{
+						{Key: aws.String("ClusterSelector"), Value: aws.String("=prod")},
+					},
+				},
+			},
+			want: []*UnboundSecret{
+				{
+					name:        "AmazonMSK_shared",
+					arn:         "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_shared-678901",
+					selectorErr: `invalid selector "=prod": empty key`,
+				},
+			},
+		}, {
+			name: "untagged",
+			give: []secretsmanagertypes.SecretListEntry{
+				{
+					Name: aws.String("AmazonMSK_legacy"),
+					ARN:  aws.String("arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_legacy-345678"),
+				},
+			},
+			want: []*UnboundSecret{
+				{
+					name: "AmazonMSK_legacy",
+					arn:  "arn:aws:secretsmanager:ap-southeast-2:123456789012:secret:AmazonMSK_legacy-345678",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findUnboundSecrets(clusters, tt.give)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
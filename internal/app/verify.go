@@ -0,0 +1,58 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mikelorant/msk-secret-binder/internal/sliceutil"
+)
+
+type Verification struct {
+	cluster    *Cluster
+	missing    []string
+	unexpected []string
+}
+
+func (v *Verification) ok() bool {
+	return len(v.missing) == 0 && len(v.unexpected) == 0
+}
+
+func verifyClustersSecrets(clusters []*Cluster) (mismatches []*Verification, err error) {
+	mismatches = []*Verification{}
+
+	for _, cluster := range clusters {
+		if !shouldApply(cluster) {
+			continue
+		}
+		v, err := verifyClusterSecrets(cluster.service.kafka, cluster, cluster.service.pageSize)
+		if err != nil {
+			return mismatches, fmt.Errorf("unable to verify %v: %w", clusterLabel(cluster), err)
+		}
+		if !v.ok() {
+			mismatches = append(mismatches, v)
+		}
+	}
+
+	return mismatches, nil
+}
+
+func verifyClusterSecrets(cl KafkaClientAPI, cluster *Cluster, pageSize int32) (*Verification, error) {
+	scramSecrets, err := listScramSecrets(cl, cluster.clusterInfo.ClusterArn, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list scram secrets: %w", err)
+	}
+
+	intended := intendedSecretArns(cluster)
+
+	return &Verification{
+		cluster:    cluster,
+		missing:    sliceutil.Diff(intended, scramSecrets),
+		unexpected: sliceutil.Diff(scramSecrets, intended),
+	}, nil
+}
+
+func intendedSecretArns(cluster *Cluster) []string {
+	cs := cluster.secretArnChangeSet
+	intended := sliceutil.Diff(cluster.assosciatedSecretArnList, cs.remove)
+
+	return append(intended, sliceutil.Diff(cs.add, intended)...)
+}
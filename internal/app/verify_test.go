@@ -0,0 +1,82 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyClusterSecrets(t *testing.T) {
+	tests := []struct {
+		name           string
+		give           []string
+		wantMissing    []string
+		wantUnexpected []string
+	}{
+		{
+			name:           "verified",
+			give:           []string{"apple", "pear"},
+			wantMissing:    []string{},
+			wantUnexpected: []string{},
+		}, {
+			name:           "missing",
+			give:           []string{"apple"},
+			wantMissing:    []string{"pear"},
+			wantUnexpected: []string{},
+		}, {
+			name:           "not_removed",
+			give:           []string{"apple", "pear", "orange"},
+			wantMissing:    []string{},
+			wantUnexpected: []string{"orange"},
+		}, {
+			name:           "added_elsewhere",
+			give:           []string{"apple", "pear", "lemon"},
+			wantMissing:    []string{},
+			wantUnexpected: []string{"lemon"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := &mockKafkaClientAPI{
+				listScramSecretsOutput: []*kafka.ListScramSecretsOutput{
+					{SecretArnList: tt.give},
+				},
+			}
+
+			cluster := &Cluster{
+				clusterInfo: &kafkatypes.ClusterInfo{
+					ClusterName: aws.String("example1"),
+					ClusterArn:  aws.String("arn:aws:kafka:ap-southeast-2:123456789012:cluster/example1/1"),
+				},
+				assosciatedSecretArnList: []string{"apple", "orange"},
+				secretArnChangeSet: &SecretChangeSet{
+					add:    []string{"pear"},
+					remove: []string{"orange"},
+				},
+			}
+
+			got, err := verifyClusterSecrets(cl, cluster, 100)
+			assert.NoError(t, err)
+			td.Cmp(t, got.missing, tt.wantMissing)
+			td.Cmp(t, got.unexpected, tt.wantUnexpected)
+			td.Cmp(t, got.ok(), len(tt.wantMissing)+len(tt.wantUnexpected) == 0)
+		})
+	}
+}
+
+func TestIntendedSecretArns(t *testing.T) {
+	cluster := &Cluster{
+		assosciatedSecretArnList: []string{"apple", "orange", "lemon"},
+		secretArnChangeSet: &SecretChangeSet{
+			add:    []string{"pear", "apple"},
+			remove: []string{"orange"},
+		},
+	}
+
+	td.Cmp(t, intendedSecretArns(cluster), []string{"apple", "lemon", "pear"})
+}
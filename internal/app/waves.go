@@ -0,0 +1,262 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/theckman/yacspin"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	_defaultWaveName = "default"
+	_waveTagPrefix   = "tag:"
+)
+
+type Wave struct {
+	name      string
+	selectors []string
+	clusters  []*Cluster
+}
+
+type waveSlice []*Wave
+
+func (w *waveSlice) String() string {
+	names := []string{}
+	for _, wave := range *w {
+		names = append(names, wave.name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (w *waveSlice) Set(value string) error {
+	wave, err := parseWave(value)
+	if err != nil {
+		return err
+	}
+	*w = append(*w, wave)
+	return nil
+}
+
+func parseWave(value string) (*Wave, error) {
+	name, selectors, ok := strings.Cut(value, "=")
+	if !ok || name == "" || selectors == "" {
+		return nil, fmt.Errorf("invalid wave %q: expected name=selector[,selector]", value)
+	}
+
+	wave := &Wave{
+		name: name,
+	}
+
+	for _, selector := range strings.Split(selectors, ",") {
+		if strings.HasPrefix(selector, _waveTagPrefix) {
+			if _, _, ok := strings.Cut(strings.TrimPrefix(selector, _waveTagPrefix), "="); !ok {
+				return nil, fmt.Errorf("invalid wave %q: expected tag:key=value", value)
+			}
+		} else if _, err := path.Match(selector, ""); err != nil {
+			return nil, fmt.Errorf("invalid wave %q: %w", value, err)
+		}
+		wave.selectors = append(wave.selectors, selector)
+	}
+
+	return wave, nil
+}
+
+func planWaves(clusters []*Cluster, waves []*Wave) []*Wave {
+	planned := []*Wave{}
+	for _, wave := range waves {
+		planned = append(planned, &Wave{
+			name:      wave.name,
+			selectors: wave.selectors,
+		})
+	}
+
+	remaining := &Wave{
+		name: _defaultWaveName,
+	}
+
+	for _, cluster := range clusters {
+		if !shouldApply(cluster) {
+			continue
+		}
+
+		matched := false
+		for _, wave := range planned {
+			if isWaveCluster(cluster, wave.selectors) {
+				wave.clusters = append(wave.clusters, cluster)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			remaining.clusters = append(remaining.clusters, cluster)
+		}
+	}
+
+	planned = append(planned, remaining)
+
+	nonEmpty := []*Wave{}
+	for _, wave := range planned {
+		if len(wave.clusters) > 0 {
+			nonEmpty = append(nonEmpty, wave)
+		}
+	}
+
+	return nonEmpty
+}
+
+func isWaveCluster(cluster *Cluster, selectors []string) bool {
+	name := aws.ToString(cluster.clusterInfo.ClusterName)
+	for _, selector := range selectors {
+		if strings.HasPrefix(selector, _waveTagPrefix) {
+			key, value, _ := strings.Cut(strings.TrimPrefix(selector, _waveTagPrefix), "=")
+			if v, ok := cluster.clusterInfo.Tags[key]; ok && v == value {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(selector, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasChanges(cluster *Cluster) bool {
+	if cluster.ignored {
+		return false
+	}
+
+	cs := cluster.secretArnChangeSet
+
+	return len(cs.add)+len(cs.remove)+len(cs.adopt) > 0
+}
+
+func shouldApply(cluster *Cluster) bool {
+	return hasChanges(cluster) && cluster.deferred == ""
+}
+
+func applyWaves(fleet *Fleet, waves []*Wave, opts *Options, plan planFunc, spin *yacspin.Spinner) error {
+	for i, wave := range waves {
+		if i > 0 {
+			if err := pauseWave(wave, opts.soak); err != nil {
+				return err
+			}
+			if err := checkWaveDrift(wave, opts, plan, spin); err != nil {
+				return err
+			}
+		}
+
+		if err := fleet.heartbeat.Refresh(); err != nil {
+			return fmt.Errorf("unable to apply wave %v: %w", wave.name, err)
+		}
+
+		for _, cluster := range deferWave(wave, opts, time.Now()) {
+			fmt.Printf("Deferring %v: %v.\n", clusterLabel(cluster), cluster.deferred)
+		}
+
+		spin.Suffix(fmt.Sprintf(" modifying clusters [wave %v/%v: %v]", i+1, len(waves), wave.name))
+		spin.Start()
+		if err := updateClustersSecrets(fleet, wave.clusters, opts.config.Concurrency, spin); err != nil {
+			spin.StopFail()
+			return fmt.Errorf("unable to apply wave %v: %w", wave.name, err)
+		}
+
+		spin.Message("verifying scram secrets")
+		mismatches, err := verifyClustersSecrets(wave.clusters)
+		if err != nil {
+			spin.StopFail()
+			return fmt.Errorf("unable to verify wave %v: %w", wave.name, err)
+		}
+		if len(mismatches) > 0 {
+			spin.StopFail()
+			fmt.Println()
+			printVerification(mismatches)
+			return fmt.Errorf("verification failed for %v clusters in wave %v", len(mismatches), wave.name)
+		}
+		spin.Stop()
+	}
+
+	return nil
+}
+
+// forEachCluster runs update for up to concurrency clusters at once. Clusters
+// matching an override with its own concurrency are also limited to that many
+// at once, and no new clusters are started after the first failure.
+func forEachCluster(clusters []*Cluster, concurrency int, update func(*Cluster) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(concurrency)
+
+	groups := map[string]chan struct{}{}
+	for _, cluster := range clusters {
+		if !shouldApply(cluster) {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		var group chan struct{}
+		if cluster.concurrency > 0 {
+			if _, ok := groups[cluster.concurrencyGroup]; !ok {
+				groups[cluster.concurrencyGroup] = make(chan struct{}, cluster.concurrency)
+			}
+			group = groups[cluster.concurrencyGroup]
+		}
+
+		cluster := cluster
+		g.Go(func() error {
+			if group != nil {
+				group <- struct{}{}
+				defer func() { <-group }()
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			return update(cluster)
+		})
+	}
+
+	return g.Wait()
+}
+
+// deferWave schedules the wave clusters again as a window may close or a
+// freeze begin while earlier waves are applied or soaking.
+func deferWave(wave *Wave, opts *Options, now time.Time) []*Cluster {
+	deferred := []*Cluster{}
+	for _, cluster := range wave.clusters {
+		if !shouldApply(cluster) {
+			continue
+		}
+		if err := scheduleCluster(cluster, opts.windows, opts.freezes, now); err != nil {
+			fmt.Printf("warning: %v\n", err)
+		}
+		if cluster.deferred != "" {
+			deferred = append(deferred, cluster)
+		}
+	}
+
+	return deferred
+}
+
+func pauseWave(wave *Wave, soak time.Duration) error {
+	if soak > 0 {
+		fmt.Printf("Soaking for %v before wave %v.\n", soak, wave.name)
+		time.Sleep(soak)
+		return nil
+	}
+
+	fmt.Printf("Press enter to apply wave %v.\n", wave.name)
+	fmt.Scanln()
+
+	return nil
+}
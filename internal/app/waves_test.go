@@ -0,0 +1,249 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWave(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want *Wave
+		err  string
+	}{
+		{
+			name: "glob",
+			give: "dev=*-dev",
+			want: &Wave{name: "dev", selectors: []string{"*-dev"}},
+		}, {
+			name: "many",
+			give: "prod=*-prd,tag:Environment=production",
+			want: &Wave{name: "prod", selectors: []string{"*-prd", "tag:Environment=production"}},
+		}, {
+			name: "missing_selector",
+			give: "dev",
+			err:  `invalid wave "dev": expected name=selector[,selector]`,
+		}, {
+			name: "invalid_tag",
+			give: "dev=tag:Environment",
+			err:  `invalid wave "dev=tag:Environment": expected tag:key=value`,
+		}, {
+			name: "invalid_glob",
+			give: "dev=[dev",
+			err:  `invalid wave "dev=[dev": syntax error in pattern`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWave(tt.give)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestPlanWaves(t *testing.T) {
+	cluster := func(name string, tags map[string]string, add []string) *Cluster {
+		return &Cluster{
+			clusterInfo: &kafkatypes.ClusterInfo{
+				ClusterName: aws.String(name),
+				Tags:        tags,
+			},
+			secretArnChangeSet: &SecretChangeSet{
+				add: add,
+			},
+		}
+	}
+
+	dev := cluster("payments-dev", nil, []string{"apple"})
+	stg := cluster("payments-stg", map[string]string{"Environment": "staging"}, []string{"apple"})
+	prd := cluster("payments-prd", nil, []string{"apple"})
+	unchanged := cluster("orders-dev", nil, []string{})
+	other := cluster("orders-prd", nil, []string{"pear"})
+
+	clusters := []*Cluster{prd, stg, dev, unchanged, other}
+
+	tests := []struct {
+		name string
+		give []*Wave
+		want [][]*Cluster
+	}{
+		{
+			name: "no_waves",
+			give: []*Wave{},
+			want: [][]*Cluster{
+				{prd, stg, dev, other},
+			},
+		}, {
+			name: "ordered",
+			give: []*Wave{
+				{name: "dev", selectors: []string{"*-dev"}},
+				{name: "stg", selectors: []string{"tag:Environment=staging"}},
+				{name: "prd", selectors: []string{"payments-prd"}},
+			},
+			want: [][]*Cluster{
+				{dev},
+				{stg},
+				{prd},
+				{other},
+			},
+		}, {
+			name: "empty_wave_dropped",
+			give: []*Wave{
+				{name: "test", selectors: []string{"*-test"}},
+				{name: "all", selectors: []string{"*"}},
+			},
+			want: [][]*Cluster{
+				{prd, stg, dev, other},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := [][]*Cluster{}
+			for _, wave := range planWaves(clusters, tt.give) {
+				got = append(got, wave.clusters)
+			}
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestDeferWave(t *testing.T) {
+	cluster := func(name string) *Cluster {
+		return &Cluster{
+			clusterInfo:        &kafkatypes.ClusterInfo{ClusterName: aws.String(name)},
+			secretArnChangeSet: &SecretChangeSet{add: []string{"apple"}},
+		}
+	}
+
+	open := cluster("payments-dev")
+	frozen := cluster("payments-prd")
+	frozen.clusterInfo.Tags = map[string]string{"msk-secret-binder:freeze": "2026-12-22T09:00:00Z/2026-12-23T00:00:00Z"}
+	unchanged := cluster("orders-prd")
+	unchanged.secretArnChangeSet = &SecretChangeSet{}
+
+	wave := &Wave{name: "prod", clusters: []*Cluster{open, frozen, unchanged}}
+
+	// The freeze began after planning so only a check before the wave sees it.
+	planned := time.Date(2026, 12, 22, 8, 0, 0, 0, time.UTC)
+	td.Cmp(t, deferWave(wave, &Options{}, planned), []*Cluster{})
+
+	applied := time.Date(2026, 12, 22, 10, 0, 0, 0, time.UTC)
+	td.Cmp(t, deferWave(wave, &Options{}, applied), []*Cluster{frozen})
+	td.Cmp(t, frozen.deferred, "freeze until 2026-12-23T00:00:00Z")
+	td.Cmp(t, open.deferred, "")
+	td.Cmp(t, shouldApply(frozen), false)
+}
+
+func TestForEachCluster(t *testing.T) {
+	cluster := func(name, group string, concurrency int) *Cluster {
+		return &Cluster{
+			clusterInfo:        &kafkatypes.ClusterInfo{ClusterName: aws.String(name)},
+			secretArnChangeSet: &SecretChangeSet{add: []string{"apple"}},
+			concurrencyGroup:   group,
+			concurrency:        concurrency,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		give        []*Cluster
+		concurrency int
+		wantMax     int
+		wantGroup   int
+		wantUpdated int
+		err         string
+	}{
+		{
+			name:        "serial",
+			give:        []*Cluster{cluster("a-dev", "", 0), cluster("b-dev", "", 0), cluster("c-dev", "", 0)},
+			concurrency: 1,
+			wantMax:     1,
+			wantUpdated: 3,
+		}, {
+			name:        "parallel",
+			give:        []*Cluster{cluster("a-dev", "", 0), cluster("b-dev", "", 0), cluster("c-dev", "", 0), cluster("d-dev", "", 0)},
+			concurrency: 2,
+			wantMax:     2,
+			wantUpdated: 4,
+		}, {
+			name:        "group",
+			give:        []*Cluster{cluster("a-prd", "*-prd", 1), cluster("b-prd", "*-prd", 1), cluster("c-prd", "*-prd", 1)},
+			concurrency: 3,
+			wantMax:     1,
+			wantGroup:   1,
+			wantUpdated: 3,
+		}, {
+			name:        "error",
+			give:        []*Cluster{cluster("fail-dev", "", 0), cluster("b-dev", "", 0), cluster("c-dev", "", 0)},
+			concurrency: 1,
+			wantMax:     1,
+			wantUpdated: 1,
+			err:         "unable to update fail-dev",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var running, max, group, maxGroup, updated int
+
+			err := forEachCluster(tt.give, tt.concurrency, func(cluster *Cluster) error {
+				mu.Lock()
+				running++
+				updated++
+				if running > max {
+					max = running
+				}
+				if cluster.concurrencyGroup != "" {
+					group++
+					if group > maxGroup {
+						maxGroup = group
+					}
+				}
+				mu.Unlock()
+
+				time.Sleep(20 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				if cluster.concurrencyGroup != "" {
+					group--
+				}
+				mu.Unlock()
+
+				name := aws.ToString(cluster.clusterInfo.ClusterName)
+				if strings.HasPrefix(name, "fail") {
+					return errors.New("unable to update " + name)
+				}
+				return nil
+			})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			td.Cmp(t, max, tt.wantMax)
+			td.Cmp(t, maxGroup, tt.wantGroup)
+			td.Cmp(t, updated, tt.wantUpdated)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package fuzzy
+
+import "strings"
+
+func Distance(a, b string) int {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func Closest(s string, candidates []string) (closest string, distance int) {
+	distance = -1
+	for _, c := range candidates {
+		d := Distance(s, c)
+		if distance == -1 || d < distance {
+			closest, distance = c, d
+		}
+	}
+
+	return closest, distance
+}
+
+func minInt(v int, vs ...int) int {
+	for _, n := range vs {
+		if n < v {
+			v = n
+		}
+	}
+
+	return v
+}
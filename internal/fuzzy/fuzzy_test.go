@@ -0,0 +1,109 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name  string
+		giveA string
+		giveB string
+		want  int
+	}{
+		{
+			name:  "equal",
+			giveA: "payments-prd",
+			giveB: "payments-prd",
+			want:  0,
+		}, {
+			name:  "substitution",
+			giveA: "payments-prd",
+			giveB: "payments-prf",
+			want:  1,
+		}, {
+			name:  "insertion",
+			giveA: "paymnts-prd",
+			giveB: "payments-prd",
+			want:  1,
+		}, {
+			name:  "deletion",
+			giveA: "payments-prod",
+			giveB: "payments-prd",
+			want:  1,
+		}, {
+			name:  "case_insensitive",
+			giveA: "Cluster",
+			giveB: "cluster",
+			want:  0,
+		}, {
+			name:  "a_is_empty",
+			giveA: "",
+			giveB: "orders",
+			want:  6,
+		}, {
+			name:  "b_is_empty",
+			giveA: "orders",
+			giveB: "",
+			want:  6,
+		}, {
+			name:  "both_empty",
+			giveA: "",
+			giveB: "",
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.giveA, tt.giveB)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestClosest(t *testing.T) {
+	tests := []struct {
+		name         string
+		give         string
+		giveCands    []string
+		want         string
+		wantDistance int
+	}{
+		{
+			name:         "exact",
+			give:         "orders-prd",
+			giveCands:    []string{"payments-prd", "orders-prd", "orders-stg"},
+			want:         "orders-prd",
+			wantDistance: 0,
+		}, {
+			name:         "typo",
+			give:         "paymnets-prd",
+			giveCands:    []string{"payments-prd", "orders-prd", "orders-stg"},
+			want:         "payments-prd",
+			wantDistance: 2,
+		}, {
+			name:         "first_wins_on_tie",
+			give:         "orders-prx",
+			giveCands:    []string{"orders-prd", "orders-prf"},
+			want:         "orders-prd",
+			wantDistance: 1,
+		}, {
+			name:         "no_candidates",
+			give:         "orders-prd",
+			giveCands:    []string{},
+			want:         "",
+			wantDistance: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, distance := Closest(tt.give, tt.giveCands)
+			td.Cmp(t, got, tt.want)
+			td.Cmp(t, distance, tt.wantDistance)
+		})
+	}
+}
@@ -17,3 +17,36 @@ func Diff(src, cmp []string) []string {
 
 	return diff
 }
+
+func Contains(src []string, s string) bool {
+	for _, v := range src {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func Intersect(src, cmp []string) []string {
+	intersect := []string{}
+	for _, s := range src {
+		if Contains(cmp, s) {
+			intersect = append(intersect, s)
+		}
+	}
+
+	return intersect
+}
+
+func Chunk(src []string, size int) [][]string {
+	chunks := [][]string{}
+	for size < len(src) {
+		src, chunks = src[size:], append(chunks, src[:size])
+	}
+	if len(src) > 0 {
+		chunks = append(chunks, src)
+	}
+
+	return chunks
+}
@@ -83,3 +83,122 @@ func TestDiff(t *testing.T) {
 		})
 	}
 }
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		name    string
+		giveSrc []string
+		give    string
+		want    bool
+	}{
+		{
+			name:    "found",
+			giveSrc: []string{"apple", "pear", "orange"},
+			give:    "pear",
+			want:    true,
+		}, {
+			name:    "not_found",
+			giveSrc: []string{"apple", "pear", "orange"},
+			give:    "lemon",
+			want:    false,
+		}, {
+			name:    "source_is_empty",
+			giveSrc: []string{},
+			give:    "pear",
+			want:    false,
+		}, {
+			name:    "source_is_nil",
+			giveSrc: nil,
+			give:    "pear",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Contains(tt.giveSrc, tt.give)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name    string
+		giveSrc []string
+		giveCmp []string
+		want    []string
+	}{
+		{
+			name:    "equal",
+			giveSrc: []string{"apple", "pear", "orange"},
+			giveCmp: []string{"orange", "apple", "pear"},
+			want:    []string{"apple", "pear", "orange"},
+		}, {
+			name:    "partial",
+			giveSrc: []string{"apple", "pear", "orange"},
+			giveCmp: []string{"pear", "lemon"},
+			want:    []string{"pear"},
+		}, {
+			name:    "disjoint",
+			giveSrc: []string{"apple", "pear"},
+			giveCmp: []string{"lemon"},
+			want:    []string{},
+		}, {
+			name:    "both_are_nil",
+			giveSrc: nil,
+			giveCmp: nil,
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Intersect(tt.giveSrc, tt.giveCmp)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name     string
+		giveSrc  []string
+		giveSize int
+		want     [][]string
+	}{
+		{
+			name:     "even",
+			giveSrc:  []string{"apple", "pear", "orange", "lemon"},
+			giveSize: 2,
+			want:     [][]string{{"apple", "pear"}, {"orange", "lemon"}},
+		}, {
+			name:     "remainder",
+			giveSrc:  []string{"apple", "pear", "orange"},
+			giveSize: 2,
+			want:     [][]string{{"apple", "pear"}, {"orange"}},
+		}, {
+			name:     "smaller_than_size",
+			giveSrc:  []string{"apple"},
+			giveSize: 2,
+			want:     [][]string{{"apple"}},
+		}, {
+			name:     "source_is_empty",
+			giveSrc:  []string{},
+			giveSize: 2,
+			want:     [][]string{},
+		}, {
+			name:     "source_is_nil",
+			giveSrc:  nil,
+			giveSize: 2,
+			want:     [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Chunk(tt.giveSrc, tt.giveSize)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
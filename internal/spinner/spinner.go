@@ -7,10 +7,10 @@ import (
 	"github.com/theckman/yacspin"
 )
 
-func NewSpinner() (s *yacspin.Spinner, err error) {
+func NewSpinner(frequency time.Duration, charSet int) (s *yacspin.Spinner, err error) {
 	return yacspin.New(yacspin.Config{
-		Frequency:         50 * time.Millisecond,
-		CharSet:           yacspin.CharSets[14],
+		Frequency:         frequency,
+		CharSet:           yacspin.CharSets[charSet],
 		Suffix:            " retrieving data",
 		StopCharacter:     "✓",
 		StopFailCharacter: "✗",
@@ -8,7 +8,7 @@ import (
 )
 
 func main() {
-	if err := app.Run(); err != nil {
+	if err := app.Run(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stdout, "error: %v\n", err)
 		os.Exit(1)
 	}